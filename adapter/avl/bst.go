@@ -0,0 +1,405 @@
+// Package avl implements bst.BST as an AVL tree, keeping the heights of every
+// node's subtrees within one of each other so that lookups stay logarithmic
+// regardless of insertion order. Every node also knows how many keys its
+// subtree holds, which makes Root a bst.OrderStatistic tree.
+package avl
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/internal/walk"
+)
+
+// Invariants verified by Root.Validate on top of the ones of bst.CheckTree.
+const (
+	InvariantHeight   bst.Invariant = "stored heights match"
+	InvariantBalanced bst.Invariant = "subtree heights differ by at most one"
+	InvariantSize     bst.Invariant = "stored subtree sizes match"
+)
+
+// NewBST creates an empty AVL tree. The arguments have the same meaning as in
+// unbalanced.NewBST.
+func NewBST[K any, V any](unique bool, creationSize int, comparer bst.Comparer[K, V]) bst.BST[K, V] {
+	if unique {
+		creationSize = 1
+	} else if creationSize <= 0 {
+		creationSize = 8
+	}
+	return &Root[K, V]{
+		unique:       unique,
+		creationSize: creationSize,
+		comparer:     comparer,
+		nodePool:     sync.Pool{New: func() any { return &node[K, V]{} }},
+	}
+}
+
+// Root is the entry point of an AVL tree.
+type Root[K any, V any] struct {
+	root         *bst.Node[K, V]
+	nodeCount    int
+	valueCount   int
+	unique       bool
+	creationSize int
+	nodePool     sync.Pool
+	comparer     bst.Comparer[K, V]
+}
+
+// node carries the height and the number of keys of the subtree it roots next
+// to the bst.Node handed out to callers, as described by walk.Outer.
+type node[K any, V any] struct {
+	bst.Node[K, V]
+	height int
+	size   int
+}
+
+func asNode[K any, V any](n *bst.Node[K, V]) *node[K, V] {
+	return walk.Outer[node[K, V]](n)
+}
+
+func height[K any, V any](n *bst.Node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return asNode(n).height
+}
+
+func size[K any, V any](n *bst.Node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return asNode(n).size
+}
+
+// Insert implements bst.BST.
+func (r *Root[K, V]) Insert(key K, value V) error {
+	if r.root == nil {
+		r.root = r.createEmptyNode(key, nil)
+		r.root.Values = append(r.root.Values, value)
+		r.nodeCount++
+		r.valueCount++
+		return nil
+	}
+	node := r.root
+	for {
+		comparison, err := r.comparer.CompareKeys(key, node.Key)
+		if err != nil {
+			return err
+		}
+		switch {
+		case comparison > 0:
+			if node.Greater == nil {
+				node.Greater = r.createEmptyNode(key, node)
+				node.Greater.Values = append(node.Greater.Values, value)
+				r.nodeCount++
+				r.valueCount++
+				r.retrace(node)
+				return nil
+			}
+			node = node.Greater
+		case comparison < 0:
+			if node.Lower == nil {
+				node.Lower = r.createEmptyNode(key, node)
+				node.Lower.Values = append(node.Lower.Values, value)
+				r.nodeCount++
+				r.valueCount++
+				r.retrace(node)
+				return nil
+			}
+			node = node.Lower
+		default:
+			if r.unique {
+				return bst.ErrUniqueViolated{Key: key}
+			}
+			node.Values = append(node.Values, value)
+			r.valueCount++
+			return nil
+		}
+	}
+}
+
+func (r *Root[K, V]) createEmptyNode(key K, parent *bst.Node[K, V]) *bst.Node[K, V] {
+	n := r.nodePool.Get().(*node[K, V])
+	n.Key = key
+	n.Values = make([]V, 0, r.creationSize)
+	n.Parent = parent
+	n.height, n.size = 1, 1
+	return &n.Node
+}
+
+func (r *Root[K, V]) releaseNode(n *bst.Node[K, V]) {
+	n.Parent, n.Lower, n.Greater = nil, nil, nil
+	n.Values = nil
+	r.nodePool.Put(asNode(n))
+}
+
+// retrace walks from n up to the root, refreshing heights and sizes and
+// rotating every node whose balance factor left the [-1, 1] range.
+func (r *Root[K, V]) retrace(n *bst.Node[K, V]) {
+	for n != nil {
+		n = r.rebalance(n).Parent
+	}
+}
+
+func (r *Root[K, V]) rebalance(n *bst.Node[K, V]) *bst.Node[K, V] {
+	update(n)
+	switch balance := balanceFactor(n); {
+	case balance > 1:
+		if balanceFactor(n.Lower) < 0 {
+			r.rotateLeft(n.Lower)
+		}
+		return r.rotateRight(n)
+	case balance < -1:
+		if balanceFactor(n.Greater) > 0 {
+			r.rotateRight(n.Greater)
+		}
+		return r.rotateLeft(n)
+	}
+	return n
+}
+
+func balanceFactor[K any, V any](n *bst.Node[K, V]) int {
+	return height(n.Lower) - height(n.Greater)
+}
+
+func update[K any, V any](n *bst.Node[K, V]) {
+	asNode(n).height = 1 + max(height(n.Lower), height(n.Greater))
+	asNode(n).size = 1 + size(n.Lower) + size(n.Greater)
+}
+
+func (r *Root[K, V]) rotateLeft(n *bst.Node[K, V]) *bst.Node[K, V] {
+	pivot := n.Greater
+	n.Greater = pivot.Lower
+	if n.Greater != nil {
+		n.Greater.Parent = n
+	}
+	r.replaceChild(n.Parent, n, pivot)
+	pivot.Lower = n
+	n.Parent = pivot
+	update(n)
+	update(pivot)
+	return pivot
+}
+
+func (r *Root[K, V]) rotateRight(n *bst.Node[K, V]) *bst.Node[K, V] {
+	pivot := n.Lower
+	n.Lower = pivot.Greater
+	if n.Lower != nil {
+		n.Lower.Parent = n
+	}
+	r.replaceChild(n.Parent, n, pivot)
+	pivot.Greater = n
+	n.Parent = pivot
+	update(n)
+	update(pivot)
+	return pivot
+}
+
+// replaceChild links child where old used to hang from parent.
+func (r *Root[K, V]) replaceChild(parent, old, child *bst.Node[K, V]) {
+	switch {
+	case parent == nil:
+		r.root = child
+	case parent.Lower == old:
+		parent.Lower = child
+	default:
+		parent.Greater = child
+	}
+	if child != nil {
+		child.Parent = parent
+	}
+}
+
+// Search implements bst.BST.
+func (r *Root[K, V]) Search(key K) (*bst.Node[K, V], error) {
+	return walk.Search(r.comparer, r.root, key)
+}
+
+// Query implements bst.BST.
+func (r *Root[K, V]) Query(query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.Query(r.comparer, r.root, query)
+}
+
+// Delete implements bst.BST.
+func (r *Root[K, V]) Delete(key K, value *V) error {
+	node, removed, err := walk.Remove(r.comparer, r.root, key, value)
+	r.valueCount -= removed
+	if err != nil || node == nil {
+		return err
+	}
+	r.nodeCount--
+
+	child := node.Lower
+	if child == nil {
+		child = node.Greater
+	}
+	parent := node.Parent
+	r.replaceChild(parent, node, child)
+	r.releaseNode(node)
+	r.retrace(parent)
+	return nil
+}
+
+// GetAll implements bst.BST.
+func (r *Root[K, V]) GetAll() iter.Seq[V] {
+	return walk.Values(r.root)
+}
+
+// All implements bst.EntryIterator.
+func (r *Root[K, V]) All() iter.Seq2[K, V] {
+	return walk.Entries(r.root)
+}
+
+// Groups implements bst.EntryIterator.
+func (r *Root[K, V]) Groups() iter.Seq2[K, []V] {
+	return walk.Groups(r.root)
+}
+
+// GroupsContext implements bst.EntryIterator.
+func (r *Root[K, V]) GroupsContext(ctx context.Context) iter.Seq2[bst.Entry[K, []V], error] {
+	return walk.GroupsContext(ctx, r.root)
+}
+
+// QueryEntries implements bst.EntryIterator.
+func (r *Root[K, V]) QueryEntries(query bst.Query[K]) iter.Seq2[bst.Entry[K, V], error] {
+	return walk.QueryEntries(r.comparer, r.root, query)
+}
+
+// QueryNodes implements bst.EntryIterator.
+func (r *Root[K, V]) QueryNodes(query bst.Query[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return walk.QueryNodes(r.comparer, r.root, query)
+}
+
+// QueryIn implements bst.SetQuerier.
+func (r *Root[K, V]) QueryIn(set bst.QuerySet[K]) iter.Seq2[V, error] {
+	return walk.QueryIn(r.comparer, r.root, set)
+}
+
+// QueryRange implements bst.RangeQuerier.
+func (r *Root[K, V]) QueryRange(locate bst.RangeFunc[K]) iter.Seq2[V, error] {
+	return walk.QueryRange(r.root, locate)
+}
+
+// QueryContext implements bst.ContextQuerier.
+func (r *Root[K, V]) QueryContext(ctx context.Context, query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.QueryContext(ctx, r.comparer, r.root, query)
+}
+
+// GetAllContext implements bst.ContextQuerier.
+func (r *Root[K, V]) GetAllContext(ctx context.Context) iter.Seq2[V, error] {
+	return walk.ValuesContext(ctx, r.root)
+}
+
+// GetAllDesc implements bst.Descender.
+func (r *Root[K, V]) GetAllDesc() iter.Seq[V] {
+	return walk.ValuesDesc(r.root)
+}
+
+// QueryDesc implements bst.Descender.
+func (r *Root[K, V]) QueryDesc(query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.QueryDesc(r.comparer, r.root, query)
+}
+
+// Cursor implements bst.Navigable.
+func (r *Root[K, V]) Cursor() *bst.Cursor[K, V] {
+	return bst.NewCursor(func() *bst.Node[K, V] { return r.root }, r.comparer)
+}
+
+// Floor implements bst.Nearest.
+func (r *Root[K, V]) Floor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Floor(r.comparer, r.root, key)
+}
+
+// Ceiling implements bst.Nearest.
+func (r *Root[K, V]) Ceiling(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Ceiling(r.comparer, r.root, key)
+}
+
+// Predecessor implements bst.Nearest.
+func (r *Root[K, V]) Predecessor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Predecessor(r.comparer, r.root, key)
+}
+
+// Successor implements bst.Nearest.
+func (r *Root[K, V]) Successor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Successor(r.comparer, r.root, key)
+}
+
+// GetMax implements bst.BST.
+func (r *Root[K, V]) GetMax() *bst.Node[K, V] {
+	if r.root == nil {
+		return nil
+	}
+	return walk.Max(r.root)
+}
+
+// GetMin implements bst.BST.
+func (r *Root[K, V]) GetMin() *bst.Node[K, V] {
+	if r.root == nil {
+		return nil
+	}
+	return walk.Min(r.root)
+}
+
+// GetNumberOfKeys implements bst.BST.
+func (r *Root[K, V]) GetNumberOfKeys() int {
+	return r.nodeCount
+}
+
+// GetNumberOfValues implements bst.Counter.
+func (r *Root[K, V]) GetNumberOfValues() int {
+	return r.valueCount
+}
+
+// Height returns the number of nodes on the longest path from the root to a
+// leaf.
+func (r *Root[K, V]) Height() int {
+	return height(r.root)
+}
+
+// Validate checks the structure of the tree with bst.CheckTree, then checks
+// that every node knows its height and size and is balanced.
+func (r *Root[K, V]) Validate() error {
+	if err := bst.CheckTree(r.root, r.comparer, r.unique, r.nodeCount, r.valueCount); err != nil {
+		return err
+	}
+	_, err := checkHeight(r.root)
+	return err
+}
+
+func checkHeight[K any, V any](n *bst.Node[K, V]) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+	lower, err := checkHeight(n.Lower)
+	if err != nil {
+		return 0, err
+	}
+	greater, err := checkHeight(n.Greater)
+	if err != nil {
+		return 0, err
+	}
+	actual := 1 + max(lower, greater)
+	switch {
+	case asNode(n).size != 1+size(n.Lower)+size(n.Greater):
+		return 0, bst.ErrInvalidTree{Key: n.Key, Invariant: InvariantSize, Detail: fmt.Sprintf("stored %d, actual %d", asNode(n).size, 1+size(n.Lower)+size(n.Greater))}
+	case asNode(n).height != actual:
+		return 0, bst.ErrInvalidTree{Key: n.Key, Invariant: InvariantHeight, Detail: fmt.Sprintf("stored %d, actual %d", asNode(n).height, actual)}
+	case lower-greater > 1 || greater-lower > 1:
+		return 0, bst.ErrInvalidTree{Key: n.Key, Invariant: InvariantBalanced, Detail: fmt.Sprintf("heights %d and %d", lower, greater)}
+	}
+	return actual, nil
+}
+
+// Update implements bst.BST.
+func (r *Root[K, V]) Update(key K, old V, nw V) error {
+	node, err := r.Search(key)
+	if err != nil || node == nil {
+		return err
+	}
+	_, err = walk.ReplaceValue(r.comparer, node, old, nw)
+	return err
+}
@@ -0,0 +1,90 @@
+package avl_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/avl"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/bsttest"
+)
+
+type BSTTestSuite struct {
+	suite.Suite
+}
+
+func (s *BSTTestSuite) TestInsertSorted() {
+	b := avl.NewBST(true, 0, comparer.NewComparer[int, int]()).(*avl.Root[int, int])
+
+	for i := range 1023 {
+		s.NoError(b.Insert(i, i))
+	}
+	s.Equal(1023, b.GetNumberOfKeys())
+	s.Equal(10, b.Height())
+	s.NoError(b.Validate())
+
+	values := make([]int, 0, 1023)
+	for v := range b.GetAll() {
+		values = append(values, v)
+	}
+	s.Len(values, 1023)
+	for i, v := range values {
+		s.Equal(i, v)
+	}
+}
+
+func (s *BSTTestSuite) TestDeleteAll() {
+	b := avl.NewBST(true, 0, comparer.NewComparer[int, int]()).(*avl.Root[int, int])
+
+	for i := range 1000 {
+		s.NoError(b.Insert((i*7919)%1000, i))
+	}
+	s.NoError(b.Validate())
+
+	for i := range 1000 {
+		s.NoError(b.Delete((i*104729)%1000, nil))
+		s.Equal(999-i, b.GetNumberOfKeys())
+		if i%100 == 0 {
+			s.NoError(b.Validate())
+		}
+	}
+	s.Nil(b.GetMin())
+	s.Nil(b.GetMax())
+	s.Equal(0, b.Height())
+}
+
+func (s *BSTTestSuite) TestValidate() {
+	b := avl.NewBST(false, 0, comparer.NewComparer[int, int]()).(*avl.Root[int, int])
+	s.NoError(b.Validate())
+	for i := range 100 {
+		s.NoError(b.Insert(i, i))
+	}
+	s.NoError(b.Validate())
+
+	var invalid bst.ErrInvalidTree
+
+	lowest, highest := b.GetMin(), b.GetMax()
+	lowest.Key, highest.Key = highest.Key, lowest.Key
+	s.ErrorAs(b.Validate(), &invalid)
+	s.Equal(bst.InvariantOrder, invalid.Invariant)
+	lowest.Key, highest.Key = highest.Key, lowest.Key
+
+	values := lowest.Values
+	lowest.Values = nil
+	s.ErrorAs(b.Validate(), &invalid)
+	s.Equal(bst.InvariantValues, invalid.Invariant)
+	s.Equal(0, invalid.Key)
+	lowest.Values = values
+	s.NoError(b.Validate())
+}
+
+func TestBSTTestSuite(t *testing.T) {
+	suite.Run(t, new(BSTTestSuite))
+}
+
+func TestConformance(t *testing.T) {
+	bsttest.Run(t, func(unique bool, comparer bst.Comparer[string, int]) bst.BST[string, int] {
+		return avl.NewBST(unique, 0, comparer)
+	})
+}
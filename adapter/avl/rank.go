@@ -0,0 +1,75 @@
+package avl
+
+import "github.com/vinicius-lino-figueiredo/bst"
+
+// Rank implements bst.OrderStatistic.
+func (r *Root[K, V]) Rank(key K) (int, error) {
+	return r.countBelow(key, false)
+}
+
+// Select implements bst.OrderStatistic.
+func (r *Root[K, V]) Select(k int) *bst.Node[K, V] {
+	if k < 0 || k >= size(r.root) {
+		return nil
+	}
+	n := r.root
+	for {
+		lower := size(n.Lower)
+		switch {
+		case k < lower:
+			n = n.Lower
+		case k > lower:
+			k -= lower + 1
+			n = n.Greater
+		default:
+			return n
+		}
+	}
+}
+
+// CountRange implements bst.OrderStatistic.
+func (r *Root[K, V]) CountRange(query bst.Query[K]) (int, error) {
+	if query.Equal != nil {
+		node, err := r.Search(*query.Equal)
+		if err != nil || node == nil {
+			return 0, err
+		}
+		return 1, nil
+	}
+	if query.GreaterThan == nil && query.LowerThan == nil {
+		return 0, nil
+	}
+	upper, lower := size(r.root), 0
+	var err error
+	if query.LowerThan != nil {
+		if upper, err = r.countBelow(query.LowerThan.Value, query.LowerThan.IncludeEqual); err != nil {
+			return 0, err
+		}
+	}
+	if query.GreaterThan != nil {
+		if lower, err = r.countBelow(query.GreaterThan.Value, !query.GreaterThan.IncludeEqual); err != nil {
+			return 0, err
+		}
+	}
+	return max(upper-lower, 0), nil
+}
+
+// countBelow returns how many keys are lower than key, counting key itself if
+// includeEqual is set.
+func (r *Root[K, V]) countBelow(key K, includeEqual bool) (int, error) {
+	count := 0
+	n := r.root
+	for n != nil {
+		comparison, err := r.comparer.CompareKeys(n.Key, key)
+		if err != nil {
+			return 0, err
+		}
+		if comparison < 0 || (comparison == 0 && includeEqual) {
+			count += size(n.Lower) + 1
+			n = n.Greater
+		} else {
+			n = n.Lower
+		}
+	}
+	return count, nil
+}
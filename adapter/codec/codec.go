@@ -0,0 +1,93 @@
+// Package codec provides bst.Codec implementations for common key and value
+// types.
+package codec
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// NewString creates a codec storing strings as their raw bytes.
+func NewString() bst.Codec[string] {
+	return String{}
+}
+
+// String implements bst.Codec for strings.
+type String struct{}
+
+// Encode implements bst.Codec.
+func (String) Encode(v string) ([]byte, error) {
+	return []byte(v), nil
+}
+
+// Decode implements bst.Codec.
+func (String) Decode(data []byte) (string, error) {
+	return string(data), nil
+}
+
+// NewBytes creates a codec storing byte slices as they are.
+func NewBytes() bst.Codec[[]byte] {
+	return Bytes{}
+}
+
+// Bytes implements bst.Codec for byte slices.
+type Bytes struct{}
+
+// Encode implements bst.Codec.
+func (Bytes) Encode(v []byte) ([]byte, error) {
+	return v, nil
+}
+
+// Decode implements bst.Codec.
+func (Bytes) Decode(data []byte) ([]byte, error) {
+	return bytes.Clone(data), nil
+}
+
+// NewFixed creates a codec for fixed-size values, such as numbers and structs
+// or arrays made of them, using encoding/binary in big-endian order.
+func NewFixed[T any]() bst.Codec[T] {
+	return Fixed[T]{}
+}
+
+// Fixed implements bst.Codec for fixed-size values.
+type Fixed[T any] struct{}
+
+// Encode implements bst.Codec.
+func (Fixed[T]) Encode(v T) ([]byte, error) {
+	return binary.Append(nil, binary.BigEndian, v)
+}
+
+// Decode implements bst.Codec.
+func (Fixed[T]) Decode(data []byte) (T, error) {
+	var v T
+	_, err := binary.Decode(data, binary.BigEndian, &v)
+	return v, err
+}
+
+// NewGob creates a codec for any value supported by encoding/gob.
+func NewGob[T any]() bst.Codec[T] {
+	return Gob[T]{}
+}
+
+// Gob implements bst.Codec using encoding/gob. Every value is encoded on its
+// own, type information included, so it is convenient rather than compact.
+type Gob[T any] struct{}
+
+// Encode implements bst.Codec.
+func (Gob[T]) Encode(v T) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode implements bst.Codec.
+func (Gob[T]) Decode(data []byte) (T, error) {
+	var v T
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&v)
+	return v, err
+}
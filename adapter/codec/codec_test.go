@@ -0,0 +1,97 @@
+package codec_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/codec"
+)
+
+type CodecTestSuite struct {
+	suite.Suite
+}
+
+func (s *CodecTestSuite) TestString() {
+	c := codec.NewString()
+	for _, v := range []string{"", "Alice", "é\x00\xff"} {
+		data, err := c.Encode(v)
+		s.NoError(err)
+		s.Equal([]byte(v), data)
+		decoded, err := c.Decode(data)
+		s.NoError(err)
+		s.Equal(v, decoded)
+	}
+}
+
+func (s *CodecTestSuite) TestBytes() {
+	c := codec.NewBytes()
+	data, err := c.Encode([]byte{1, 2, 3})
+	s.NoError(err)
+	s.Equal([]byte{1, 2, 3}, data)
+
+	// decoded slices do not share the buffer they were read from
+	decoded, err := c.Decode(data)
+	s.NoError(err)
+	s.Equal([]byte{1, 2, 3}, decoded)
+	data[0] = 0
+	s.Equal([]byte{1, 2, 3}, decoded)
+}
+
+func (s *CodecTestSuite) TestFixed() {
+	c := codec.NewFixed[int64]()
+	data, err := c.Encode(-2)
+	s.NoError(err)
+	s.Equal([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe}, data)
+	decoded, err := c.Decode(data)
+	s.NoError(err)
+	s.Equal(int64(-2), decoded)
+
+	type point struct {
+		X, Y int32
+	}
+	points := codec.NewFixed[point]()
+	data, err = points.Encode(point{1, -1})
+	s.NoError(err)
+	s.Len(data, 8)
+	p, err := points.Decode(data)
+	s.NoError(err)
+	s.Equal(point{1, -1}, p)
+
+	// big-endian encodings of unsigned numbers sort like the numbers
+	unsigned := codec.NewFixed[uint16]()
+	low, err := unsigned.Encode(255)
+	s.NoError(err)
+	high, err := unsigned.Encode(256)
+	s.NoError(err)
+	s.Less(string(low), string(high))
+
+	_, err = c.Decode([]byte{1, 2, 3})
+	s.Error(err)
+	_, err = codec.NewFixed[string]().Encode("variable")
+	s.Error(err)
+}
+
+func (s *CodecTestSuite) TestGob() {
+	type document struct {
+		Name    string
+		Tags    []string
+		Created time.Time
+	}
+	c := codec.NewGob[document]()
+	v := document{Name: "Alice", Tags: []string{"a", "b"}, Created: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	data, err := c.Encode(v)
+	s.NoError(err)
+	decoded, err := c.Decode(data)
+	s.NoError(err)
+	s.Equal(v, decoded)
+
+	_, err = c.Decode([]byte("not gob"))
+	s.Error(err)
+	_, err = codec.NewGob[func()]().Encode(func() {})
+	s.Error(err)
+}
+
+func TestCodecTestSuite(t *testing.T) {
+	suite.Run(t, new(CodecTestSuite))
+}
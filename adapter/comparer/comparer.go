@@ -2,6 +2,7 @@
 package comparer
 
 import (
+	"bytes"
 	"cmp"
 
 	"github.com/vinicius-lino-figueiredo/bst"
@@ -24,3 +25,22 @@ func (c Comparer[K, V]) CompareKeys(a K, b K) (int, error) {
 func (c Comparer[K, V]) CompareValues(a V, b V) (bool, error) {
 	return a == b, nil
 }
+
+// NewBytesComparer creates a comparer for []byte keys, ordering them byte by
+// byte like bytes.Compare.
+func NewBytesComparer[V comparable]() bst.Comparer[[]byte, V] {
+	return BytesComparer[V]{}
+}
+
+// BytesComparer compares []byte keys with bytes.Compare.
+type BytesComparer[V comparable] struct{}
+
+// CompareKeys implements bst.Comparer.
+func (c BytesComparer[V]) CompareKeys(a []byte, b []byte) (int, error) {
+	return bytes.Compare(a, b), nil
+}
+
+// CompareValues implements bst.Comparer.
+func (c BytesComparer[V]) CompareValues(a V, b V) (bool, error) {
+	return a == b, nil
+}
@@ -0,0 +1,168 @@
+package comparer
+
+import (
+	"cmp"
+	"fmt"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// ErrField wraps an error returned while comparing the field at Index,
+// counting from zero, of two composite keys.
+type ErrField struct {
+	Index int
+	Err   error
+}
+
+func (e ErrField) Error() string {
+	return fmt.Sprintf("field %d: %v", e.Index, e.Err)
+}
+
+func (e ErrField) Unwrap() error {
+	return e.Err
+}
+
+// Field is one of the fields a Composite comparer orders keys of type K by.
+type Field[K any] struct {
+	compare func(a, b K) (int, error)
+	// compareTo compares the field of key to value, which must be of the type
+	// of the field.
+	compareTo  func(key K, value any) (int, error)
+	descending bool
+}
+
+// NewField creates a field extracted from keys by extract and compared by
+// compare, in ascending order. The CompareKeys method of a bst.Comparer can be
+// used as compare.
+func NewField[K any, F any](extract func(K) F, compare func(a, b F) (int, error)) Field[K] {
+	return Field[K]{
+		compare: func(a, b K) (int, error) {
+			return compare(extract(a), extract(b))
+		},
+		compareTo: func(key K, value any) (int, error) {
+			v, ok := value.(F)
+			if !ok {
+				return 0, fmt.Errorf("cannot compare %T to a field of type %T", value, v)
+			}
+			return compare(extract(key), v)
+		},
+	}
+}
+
+// OrderedField creates a field extracted from keys by extract and compared
+// with cmp.Compare, in ascending order.
+func OrderedField[K any, F cmp.Ordered](extract func(K) F) Field[K] {
+	return NewField(extract, func(a, b F) (int, error) {
+		return cmp.Compare(a, b), nil
+	})
+}
+
+// Desc returns a copy of f ordered in descending order.
+func (f Field[K]) Desc() Field[K] {
+	f.descending = true
+	return f
+}
+
+// NewComposite creates a comparer ordering keys by each of fields in turn:
+// keys are ordered by the first field they differ in. Errors returned while
+// comparing a field are wrapped in ErrField.
+func NewComposite[K any, V comparable](fields ...Field[K]) bst.Comparer[K, V] {
+	return Composite[K, V]{fields: fields}
+}
+
+// Composite orders keys made of several fields.
+type Composite[K any, V comparable] struct {
+	fields []Field[K]
+}
+
+// CompareKeys implements bst.Comparer.
+func (c Composite[K, V]) CompareKeys(a K, b K) (int, error) {
+	for i, field := range c.fields {
+		comparison, err := field.compare(a, b)
+		if err != nil {
+			return 0, ErrField{Index: i, Err: err}
+		}
+		if comparison != 0 {
+			if field.descending {
+				return -comparison, nil
+			}
+			return comparison, nil
+		}
+	}
+	return 0, nil
+}
+
+// Partial describes the range of composite keys whose leading fields equal
+// Equal, in order, and whose next field, if bounded, lies between GreaterThan
+// and LowerThan. The remaining fields are left free, so no sentinel values are
+// needed for them. Values must have the types of the fields they bound, and
+// bounds apply to values whatever the direction of the field.
+type Partial struct {
+	Equal       []any
+	GreaterThan *bst.Bound[any]
+	LowerThan   *bst.Bound[any]
+}
+
+// Range returns a function locating keys relative to the range described by
+// partial, for trees ordered by c. Errors returned while comparing a field,
+// including bounds of the wrong type or beyond the last field, are wrapped in
+// ErrField.
+func (c Composite[K, V]) Range(partial Partial) bst.RangeFunc[K] {
+	return func(key K) (int, error) {
+		for i, value := range partial.Equal {
+			comparison, err := c.compareTo(i, key, value)
+			if err != nil {
+				return 0, err
+			}
+			if comparison != 0 {
+				return c.oriented(i, comparison), nil
+			}
+		}
+		i := len(partial.Equal)
+		if b := partial.GreaterThan; b != nil {
+			comparison, err := c.compareTo(i, key, b.Value)
+			if err != nil {
+				return 0, err
+			}
+			if comparison < 0 || (comparison == 0 && !b.IncludeEqual) {
+				return c.oriented(i, -1), nil
+			}
+		}
+		if b := partial.LowerThan; b != nil {
+			comparison, err := c.compareTo(i, key, b.Value)
+			if err != nil {
+				return 0, err
+			}
+			if comparison > 0 || (comparison == 0 && !b.IncludeEqual) {
+				return c.oriented(i, 1), nil
+			}
+		}
+		return 0, nil
+	}
+}
+
+// compareTo compares the field at index of key to value.
+func (c Composite[K, V]) compareTo(index int, key K, value any) (int, error) {
+	if index >= len(c.fields) {
+		return 0, ErrField{Index: index, Err: fmt.Errorf("only %d fields", len(c.fields))}
+	}
+	comparison, err := c.fields[index].compareTo(key, value)
+	if err != nil {
+		return 0, ErrField{Index: index, Err: err}
+	}
+	return comparison, nil
+}
+
+// oriented turns a comparison of the field at index into one following the
+// direction of that field.
+func (c Composite[K, V]) oriented(index int, comparison int) int {
+	if c.fields[index].descending {
+		return -comparison
+	}
+	return comparison
+}
+
+// CompareValues implements bst.Comparer.
+func (c Composite[K, V]) CompareValues(a V, b V) (bool, error) {
+	return a == b, nil
+}
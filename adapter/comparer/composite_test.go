@@ -0,0 +1,176 @@
+package comparer_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/unbalanced"
+)
+
+type document struct {
+	tenant    string
+	createdAt time.Time
+	id        int
+}
+
+type CompositeTestSuite struct {
+	suite.Suite
+	comparer bst.Comparer[document, int]
+}
+
+func (s *CompositeTestSuite) SetupTest() {
+	s.comparer = comparer.NewComposite[document, int](
+		comparer.OrderedField(func(d document) string { return d.tenant }),
+		comparer.NewField(func(d document) time.Time { return d.createdAt }, func(a, b time.Time) (int, error) {
+			return a.Compare(b), nil
+		}).Desc(),
+		comparer.NewField(func(d document) int { return d.id }, comparer.NewComparer[int, int]().CompareKeys),
+	)
+}
+
+func (s *CompositeTestSuite) TestCompareKeys() {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, c := range []struct {
+		a, b     document
+		expected int
+	}{
+		{document{"a", day, 1}, document{"a", day, 1}, 0},
+		{document{"a", day, 9}, document{"b", day, 1}, -1},
+		{document{"b", day, 1}, document{"a", day.Add(time.Hour), 9}, 1},
+		// newer documents come first
+		{document{"a", day.Add(time.Hour), 9}, document{"a", day, 1}, -1},
+		{document{"a", day, 1}, document{"a", day, 2}, -1},
+	} {
+		comparison, err := s.comparer.CompareKeys(c.a, c.b)
+		s.NoError(err)
+		s.Equal(c.expected, comparison, "%v %v", c.a, c.b)
+	}
+
+	equal, err := s.comparer.CompareValues(1, 1)
+	s.NoError(err)
+	s.True(equal)
+}
+
+func (s *CompositeTestSuite) TestTree() {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := unbalanced.NewBST(true, 0, s.comparer)
+	s.NoError(b.Insert(document{"b", day, 1}, 1))
+	s.NoError(b.Insert(document{"a", day, 2}, 2))
+	s.NoError(b.Insert(document{"a", day.Add(time.Hour), 3}, 3))
+	s.NoError(b.Insert(document{"a", day, 1}, 4))
+	s.ErrorAs(b.Insert(document{"a", day, 1}, 5), &bst.ErrUniqueViolated{})
+
+	values := make([]int, 0, 4)
+	for v := range b.GetAll() {
+		values = append(values, v)
+	}
+	s.Equal([]int{3, 4, 2, 1}, values)
+}
+
+func (s *CompositeTestSuite) TestErrors() {
+	errField := errors.New("cannot compare")
+	calls := 0
+	c := comparer.NewComposite[[2]int, int](
+		comparer.OrderedField(func(k [2]int) int { return k[0] }),
+		comparer.NewField(func(k [2]int) int { return k[1] }, func(a, b int) (int, error) {
+			calls++
+			return 0, errField
+		}),
+	)
+
+	// the first differing field decides, without comparing the next ones
+	comparison, err := c.CompareKeys([2]int{1, 0}, [2]int{2, 0})
+	s.NoError(err)
+	s.Equal(-1, comparison)
+	s.Zero(calls)
+
+	_, err = c.CompareKeys([2]int{1, 0}, [2]int{1, 0})
+	s.ErrorIs(err, errField)
+	s.Equal(comparer.ErrField{Index: 1, Err: errField}, err)
+	s.EqualError(err, "field 1: cannot compare")
+	s.Equal(1, calls)
+}
+
+func TestCompositeTestSuite(t *testing.T) {
+	suite.Run(t, new(CompositeTestSuite))
+}
+
+func (s *CompositeTestSuite) TestRange() {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := unbalanced.NewBST(true, 0, s.comparer)
+	docs := make([]document, 0, 60)
+	for i := range 60 {
+		doc := document{tenant: string(rune('a' + i%3)), createdAt: day.Add(time.Duration(i%5) * time.Hour), id: i}
+		docs = append(docs, doc)
+		s.NoError(b.Insert(doc, i))
+	}
+
+	composite := s.comparer.(comparer.Composite[document, int])
+	for _, c := range []struct {
+		name    string
+		partial comparer.Partial
+		match   func(document) bool
+	}{
+		{"tenant", comparer.Partial{Equal: []any{"b"}}, func(d document) bool {
+			return d.tenant == "b"
+		}},
+		{"tenant and time range", comparer.Partial{
+			Equal:       []any{"b"},
+			GreaterThan: &bst.Bound[any]{Value: day.Add(time.Hour), IncludeEqual: true},
+			LowerThan:   &bst.Bound[any]{Value: day.Add(3 * time.Hour)},
+		}, func(d document) bool {
+			return d.tenant == "b" && !d.createdAt.Before(day.Add(time.Hour)) && d.createdAt.Before(day.Add(3*time.Hour))
+		}},
+		{"tenant and time", comparer.Partial{Equal: []any{"c", day.Add(2 * time.Hour)}}, func(d document) bool {
+			return d.tenant == "c" && d.createdAt.Equal(day.Add(2*time.Hour))
+		}},
+		{"tenants after a", comparer.Partial{GreaterThan: &bst.Bound[any]{Value: "a"}}, func(d document) bool {
+			return d.tenant > "a"
+		}},
+		{"full key", comparer.Partial{Equal: []any{"a", day, 15}}, func(d document) bool {
+			return d.id == 15
+		}},
+	} {
+		expected := make([]int, 0, 60)
+		for doc, v := range b.(bst.EntryIterator[document, int]).All() {
+			if c.match(doc) {
+				expected = append(expected, v)
+			}
+		}
+		s.NotEmpty(expected, c.name)
+
+		values := make([]int, 0, len(expected))
+		for v, err := range b.(bst.RangeQuerier[document, int]).QueryRange(composite.Range(c.partial)) {
+			s.NoError(err)
+			values = append(values, v)
+		}
+		s.Equal(expected, values, c.name)
+	}
+
+	for _, c := range []struct {
+		partial comparer.Partial
+		index   int
+	}{
+		{comparer.Partial{Equal: []any{1}}, 0},
+		{comparer.Partial{Equal: []any{"a"}, LowerThan: &bst.Bound[any]{Value: "tomorrow"}}, 1},
+		{comparer.Partial{Equal: []any{"a", day, 0}, GreaterThan: &bst.Bound[any]{Value: 1}}, 3},
+		{comparer.Partial{Equal: []any{"a", day, 0, 1}}, 3},
+	} {
+		_, err := composite.Range(c.partial)(docs[0])
+		var errField comparer.ErrField
+		s.ErrorAs(err, &errField)
+		s.Equal(c.index, errField.Index)
+	}
+
+	// extra values stop the query instead of indexing past the last field
+	yielded := 0
+	for _, err := range b.(bst.RangeQuerier[document, int]).QueryRange(composite.Range(comparer.Partial{Equal: []any{"a", day, 0, 1}})) {
+		s.ErrorAs(err, &comparer.ErrField{})
+		yielded++
+	}
+	s.Equal(1, yielded)
+}
@@ -0,0 +1,211 @@
+package comparer
+
+import (
+	"cmp"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// ErrUnsupportedType is returned by Mixed when a key, or a value nested in
+// one, has a type it cannot order.
+type ErrUnsupportedType struct {
+	Type reflect.Type
+}
+
+func (e ErrUnsupportedType) Error() string {
+	return fmt.Sprintf("unsupported key type %v", e.Type)
+}
+
+// NewMixedComparer creates a comparer for keys of varying types, as found in
+// schemaless documents. It follows the order NeDB uses:
+//
+//	nil < nil pointers < numbers < strings < booleans < time.Time < slices < maps
+//
+// Keys of different kinds are ordered by kind, and keys of the same kind by
+// value. All integer and floating point kinds are compared as numbers; an
+// integer and a float are compared as float64. Slices and arrays are compared
+// element by element, then by length. Maps are compared entry by entry, with
+// keys sorted by this same order, then by length. Non-nil pointers are
+// compared as the values they point to. Any other type, including structs
+// other than time.Time, makes CompareKeys return ErrUnsupportedType. Values
+// nested in slices and maps are only checked once they are compared.
+func NewMixedComparer[V comparable]() bst.Comparer[any, V] {
+	return Mixed[V]{}
+}
+
+// Mixed compares keys of varying types with a total order.
+type Mixed[V comparable] struct{}
+
+// the kinds of keys Mixed orders, lowest first.
+const (
+	rankNil = iota
+	rankNull
+	rankNumber
+	rankString
+	rankBool
+	rankTime
+	rankSlice
+	rankMap
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// CompareKeys implements bst.Comparer.
+func (c Mixed[V]) CompareKeys(a any, b any) (int, error) {
+	return compareMixed(reflect.ValueOf(a), reflect.ValueOf(b))
+}
+
+// CompareValues implements bst.Comparer.
+func (c Mixed[V]) CompareValues(a V, b V) (bool, error) {
+	return a == b, nil
+}
+
+func compareMixed(a, b reflect.Value) (int, error) {
+	a, rankA, err := rank(a)
+	if err != nil {
+		return 0, err
+	}
+	b, rankB, err := rank(b)
+	if err != nil {
+		return 0, err
+	}
+	if rankA != rankB {
+		return cmp.Compare(rankA, rankB), nil
+	}
+
+	switch rankA {
+	case rankNumber:
+		return compareNumbers(a, b), nil
+	case rankString:
+		return cmp.Compare(a.String(), b.String()), nil
+	case rankBool:
+		return compareBools(a.Bool(), b.Bool()), nil
+	case rankTime:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time)), nil
+	case rankSlice:
+		return compareSlices(a, b)
+	case rankMap:
+		return compareMaps(a, b)
+	}
+	return 0, nil
+}
+
+// rank dereferences v and tells which kind of key it is.
+func rank(v reflect.Value) (reflect.Value, int, error) {
+	for v.IsValid() && (v.Kind() == reflect.Interface || v.Kind() == reflect.Pointer) {
+		if v.Kind() == reflect.Pointer && v.IsNil() {
+			return v, rankNull, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return v, rankNil, nil
+	}
+	if v.Type() == timeType {
+		return v, rankTime, nil
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return v, rankNumber, nil
+	case reflect.String:
+		return v, rankString, nil
+	case reflect.Bool:
+		return v, rankBool, nil
+	case reflect.Slice, reflect.Array:
+		return v, rankSlice, nil
+	case reflect.Map:
+		return v, rankMap, nil
+	}
+	return v, 0, ErrUnsupportedType{Type: v.Type()}
+}
+
+// compareNumbers compares two values of numeric kinds without losing
+// precision, unless one of them is a float.
+func compareNumbers(a, b reflect.Value) int {
+	switch {
+	case a.CanInt() && b.CanInt():
+		return cmp.Compare(a.Int(), b.Int())
+	case a.CanUint() && b.CanUint():
+		return cmp.Compare(a.Uint(), b.Uint())
+	case a.CanInt() && b.CanUint():
+		if a.Int() < 0 {
+			return -1
+		}
+		return cmp.Compare(uint64(a.Int()), b.Uint())
+	case a.CanUint() && b.CanInt():
+		return -compareNumbers(b, a)
+	}
+	return cmp.Compare(toFloat(a), toFloat(b))
+}
+
+func toFloat(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func compareBools(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case b:
+		return -1
+	}
+	return 1
+}
+
+func compareSlices(a, b reflect.Value) (int, error) {
+	for i := range min(a.Len(), b.Len()) {
+		comparison, err := compareMixed(a.Index(i), b.Index(i))
+		if err != nil || comparison != 0 {
+			return comparison, err
+		}
+	}
+	return cmp.Compare(a.Len(), b.Len()), nil
+}
+
+func compareMaps(a, b reflect.Value) (int, error) {
+	keysA, err := sortedKeys(a)
+	if err != nil {
+		return 0, err
+	}
+	keysB, err := sortedKeys(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range min(len(keysA), len(keysB)) {
+		comparison, err := compareMixed(keysA[i], keysB[i])
+		if err != nil || comparison != 0 {
+			return comparison, err
+		}
+		comparison, err = compareMixed(a.MapIndex(keysA[i]), b.MapIndex(keysB[i]))
+		if err != nil || comparison != 0 {
+			return comparison, err
+		}
+	}
+	return cmp.Compare(len(keysA), len(keysB)), nil
+}
+
+// sortedKeys returns the keys of m in ascending order.
+func sortedKeys(m reflect.Value) ([]reflect.Value, error) {
+	keys := m.MapKeys()
+	var err error
+	slices.SortFunc(keys, func(a, b reflect.Value) int {
+		comparison, cmpErr := compareMixed(a, b)
+		if cmpErr != nil && err == nil {
+			err = cmpErr
+		}
+		return comparison
+	})
+	return keys, err
+}
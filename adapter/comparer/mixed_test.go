@@ -0,0 +1,153 @@
+package comparer_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/unbalanced"
+)
+
+type MixedTestSuite struct {
+	suite.Suite
+	comparer bst.Comparer[any, int]
+}
+
+func (s *MixedTestSuite) SetupTest() {
+	s.comparer = comparer.NewMixedComparer[int]()
+}
+
+func (s *MixedTestSuite) TestOrder() {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	seven := 7
+	var eight any = 8
+	// each key is lower than the next one
+	ordered := []any{
+		nil,
+		(*int)(nil),
+		math.Inf(-1),
+		int8(-3),
+		-2.5,
+		uint(0),
+		0.5,
+		1,
+		&seven,
+		&eight,
+		uint64(math.MaxUint64),
+		"",
+		"a",
+		"ab",
+		"b",
+		false,
+		true,
+		day,
+		day.Add(time.Second),
+		[]any{},
+		[]any{nil},
+		[]any{1, "a"},
+		[]any{1, "a", 0},
+		[2]any{1, "b"},
+		[]int{2},
+		[]byte("c"),
+		map[string]any{},
+		map[string]any{"a": 1, "z": 1},
+		map[string]any{"a": 2},
+		map[string]any{"b": nil},
+		map[string]any{"b": nil, "c": 0},
+	}
+	for i, a := range ordered {
+		for j, b := range ordered {
+			comparison, err := s.comparer.CompareKeys(a, b)
+			s.NoError(err)
+			s.Equal(cmpInt(i, j), comparison, "%#v %#v", a, b)
+		}
+	}
+}
+
+func (s *MixedTestSuite) TestEqual() {
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	type name string
+	for _, c := range [][2]any{
+		{1, 1.0},
+		{int8(1), uint64(1)},
+		{float32(0.5), 0.5},
+		{"a", name("a")},
+		{day, day.In(time.FixedZone("", 3600))},
+		{[]any{1, "a"}, [2]any{1.0, "a"}},
+		{map[string]any{"b": 1, "a": []any{}}, map[any]any{"a": []int{}, "b": uint(1)}},
+	} {
+		comparison, err := s.comparer.CompareKeys(c[0], c[1])
+		s.NoError(err)
+		s.Zero(comparison, "%#v %#v", c[0], c[1])
+	}
+}
+
+func (s *MixedTestSuite) TestUnsupported() {
+	for _, c := range []struct {
+		key    any
+		typ    string
+		nested bool
+	}{
+		{struct{}{}, "struct {}", false},
+		{make(chan int), "chan int", false},
+		{[]any{1, func() {}}, "func()", true},
+		{map[string]any{"a": complex(1, 1)}, "complex128", true},
+		{map[any]any{1: 1, struct{}{}: 1}, "struct {}", true},
+	} {
+		_, err := s.comparer.CompareKeys(c.key, c.key)
+		var unsupported comparer.ErrUnsupportedType
+		s.ErrorAs(err, &unsupported, "%#v", c.key)
+		s.EqualError(err, "unsupported key type "+c.typ)
+
+		// nested values are not reached when the kinds differ
+		_, err = s.comparer.CompareKeys(1, c.key)
+		if c.nested {
+			s.NoError(err)
+		} else {
+			s.ErrorAs(err, &unsupported, "%#v", c.key)
+		}
+	}
+}
+
+func (s *MixedTestSuite) TestTree() {
+	b := unbalanced.NewBST(true, 0, s.comparer)
+	keys := []any{"b", 2, true, nil, []any{1}, 1.5, map[string]any{"a": 1}, "a", false}
+	for i, key := range keys {
+		s.NoError(b.Insert(key, i))
+	}
+	s.ErrorAs(b.Insert(2.0, 9), &bst.ErrUniqueViolated{})
+	s.ErrorAs(b.Insert(struct{}{}, 9), &comparer.ErrUnsupportedType{})
+
+	values := make([]int, 0, len(keys))
+	for v := range b.GetAll() {
+		values = append(values, v)
+	}
+	s.Equal([]int{3, 5, 1, 7, 0, 8, 2, 4, 6}, values)
+
+	values = values[:0]
+	for v, err := range b.Query(bst.Query[any]{
+		GreaterThan: &bst.Bound[any]{Value: 0},
+		LowerThan:   &bst.Bound[any]{Value: true},
+	}) {
+		s.NoError(err)
+		values = append(values, v)
+	}
+	s.Equal([]int{5, 1, 7, 0, 8}, values)
+}
+
+func cmpInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func TestMixedTestSuite(t *testing.T) {
+	suite.Run(t, new(MixedTestSuite))
+}
@@ -0,0 +1,31 @@
+package comparer
+
+import "github.com/vinicius-lino-figueiredo/bst"
+
+// Prefix returns a query matching every key starting with prefix, for trees
+// ordering their keys byte by byte, as Comparer does with strings and
+// BytesComparer with byte slices. Since UTF-8 preserves the order of code
+// points, this also holds for strings compared by code point.
+func Prefix[K ~string | ~[]byte](prefix K) bst.Query[K] {
+	query := bst.Query[K]{GreaterThan: &bst.Bound[K]{Value: prefix, IncludeEqual: true}}
+	if end, ok := PrefixEnd(prefix); ok {
+		query.LowerThan = &bst.Bound[K]{Value: end}
+	}
+	return query
+}
+
+// PrefixEnd returns the lowest key, in byte order, greater than every key
+// starting with prefix: prefix without its trailing 0xFF bytes, with its last
+// byte incremented. The result may not be valid UTF-8, which does not matter
+// for comparisons. There is no such key, and ok is false, if prefix is empty or
+// only holds 0xFF bytes: then every key is lower than it.
+func PrefixEnd[K ~string | ~[]byte](prefix K) (end K, ok bool) {
+	buf := append([]byte(nil), prefix...)
+	for i := len(buf) - 1; i >= 0; i-- {
+		if buf[i] < 0xFF {
+			buf[i]++
+			return K(buf[:i+1]), true
+		}
+	}
+	return end, false
+}
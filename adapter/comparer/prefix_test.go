@@ -0,0 +1,60 @@
+package comparer_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+)
+
+type PrefixTestSuite struct {
+	suite.Suite
+}
+
+func (s *PrefixTestSuite) TestPrefixEnd() {
+	for _, c := range []struct {
+		prefix string
+		end    string
+		ok     bool
+	}{
+		{"tenant/123/", "tenant/1230", true},
+		{"a", "b", true},
+		{"é", "ê", true},
+		{"ÿ", "\xc3\xc0", true},
+		{"\U0010FFFF", "\xf4\x8f\xbf\xc0", true},
+		{"a\xff\xff", "b", true},
+		{"\xff\xff", "", false},
+		{"", "", false},
+	} {
+		end, ok := comparer.PrefixEnd(c.prefix)
+		s.Equal(c.ok, ok, "%q", c.prefix)
+		s.Equal(c.end, end, "%q", c.prefix)
+
+		bytesEnd, ok := comparer.PrefixEnd([]byte(c.prefix))
+		s.Equal(c.ok, ok, "%q", c.prefix)
+		s.Equal(c.end, string(bytesEnd), "%q", c.prefix)
+	}
+
+	// the prefix itself is left untouched
+	prefix := []byte{'a', 0xff}
+	end, ok := comparer.PrefixEnd(prefix)
+	s.True(ok)
+	s.Equal([]byte{'b'}, end)
+	s.Equal([]byte{'a', 0xff}, prefix)
+}
+
+func (s *PrefixTestSuite) TestPrefix() {
+	query := comparer.Prefix("ab")
+	s.Equal("ab", query.GreaterThan.Value)
+	s.True(query.GreaterThan.IncludeEqual)
+	s.Equal("ac", query.LowerThan.Value)
+	s.False(query.LowerThan.IncludeEqual)
+
+	query = comparer.Prefix("\xff")
+	s.Equal("\xff", query.GreaterThan.Value)
+	s.Nil(query.LowerThan)
+}
+
+func TestPrefixTestSuite(t *testing.T) {
+	suite.Run(t, new(PrefixTestSuite))
+}
@@ -0,0 +1,262 @@
+// Package concurrent makes any bst.BST safe for use by multiple goroutines.
+//
+// Reads share a sync.RWMutex and writes hold it exclusively. Iterators only
+// hold the read lock while they walk the tree, never while the caller's loop
+// body runs, so the body may write to the tree without deadlocking. Instead,
+// an iterator resuming after a write that changed the tree stops: Query yields
+// a final bst.ErrConcurrentModification and GetAll, which cannot report
+// errors, just ends early.
+//
+// Nodes returned by Search, GetMin and GetMax are shared with the tree, and
+// must not be read while other goroutines may be writing to it.
+//
+// Optional interfaces are forwarded under the same lock when the wrapped tree
+// implements all of the ones the adapters of this module share: NewBST then
+// returns an Extended tree, or an Ordered one if it is a bst.OrderStatistic
+// too. Otherwise the wrapped tree only keeps bst.ContextQuerier, so that type
+// assertions never reach it without the lock. bst.Navigable is never
+// forwarded, as cursors step through nodes without holding any lock.
+package concurrent
+
+import (
+	"context"
+	"iter"
+	"reflect"
+	"slices"
+	"sync"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// NewBST wraps tree, which must not be used directly afterwards.
+func NewBST[K any, V any](tree bst.BST[K, V]) bst.BST[K, V] {
+	extended, ok := tree.(extendedTree[K, V])
+	if !ok {
+		return &Tree[K, V]{tree: tree}
+	}
+	if _, ok = tree.(bst.OrderStatistic[K, V]); ok {
+		return &Ordered[K, V]{Extended: Extended[K, V]{Tree: Tree[K, V]{tree: tree}, extended: extended}}
+	}
+	return &Extended[K, V]{Tree: Tree[K, V]{tree: tree}, extended: extended}
+}
+
+// Tree guards a bst.BST with a sync.RWMutex.
+type Tree[K any, V any] struct {
+	mu   sync.RWMutex
+	tree bst.BST[K, V]
+	// version changes on every write that changed the tree, letting
+	// iterators detect writes made while they were suspended.
+	version uint64
+}
+
+// Insert implements bst.BST.
+func (t *Tree[K, V]) Insert(key K, value V) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.modified(t.tree.Insert(key, value))
+}
+
+// Update implements bst.BST. Iterators are only stopped by updates that
+// replaced a value, told apart by comparing the values of key before and after
+// the update.
+func (t *Tree[K, V]) Update(key K, old V, nw V) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	node, err := t.tree.Search(key)
+	if err != nil {
+		return t.modified(t.tree.Update(key, old, nw))
+	}
+	if node == nil {
+		return t.tree.Update(key, old, nw)
+	}
+	values := slices.Clone(node.Values)
+	if err = t.tree.Update(key, old, nw); err != nil {
+		return err
+	}
+	if node, err = t.tree.Search(key); err != nil || node == nil || !reflect.DeepEqual(values, node.Values) {
+		t.version++
+	}
+	return nil
+}
+
+// Delete implements bst.BST. If the wrapped tree is a bst.Counter, iterators
+// are only stopped by deletions that removed a value.
+func (t *Tree[K, V]) Delete(key K, value *V) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	counter, ok := t.tree.(bst.Counter)
+	if !ok {
+		return t.modified(t.tree.Delete(key, value))
+	}
+	count := counter.GetNumberOfValues()
+	err := t.tree.Delete(key, value)
+	if counter.GetNumberOfValues() != count {
+		t.version++
+	}
+	return err
+}
+
+// modified records a write unless it failed, returning err.
+func (t *Tree[K, V]) modified(err error) error {
+	if err == nil {
+		t.version++
+	}
+	return err
+}
+
+// Search implements bst.BST.
+func (t *Tree[K, V]) Search(key K) (*bst.Node[K, V], error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.Search(key)
+}
+
+// Query implements bst.BST. It yields bst.ErrConcurrentModification and stops
+// if the tree was written to while the iteration was suspended.
+func (t *Tree[K, V]) Query(query bst.Query[K]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		t.mu.RLock()
+		guard(t, t.tree.Query(query), yield)
+	}
+}
+
+// QueryContext implements bst.ContextQuerier. If the wrapped tree does not
+// implement it, ctx is only checked between the values yielded by Query.
+func (t *Tree[K, V]) QueryContext(ctx context.Context, query bst.Query[K]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		t.mu.RLock()
+		if tree, ok := t.tree.(bst.ContextQuerier[K, V]); ok {
+			guard(t, tree.QueryContext(ctx, query), yield)
+			return
+		}
+		guard(t, checked(ctx, t.tree.Query(query)), yield)
+	}
+}
+
+// GetAll implements bst.BST. It stops early if the tree was written to while
+// the iteration was suspended.
+func (t *Tree[K, V]) GetAll() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		t.mu.RLock()
+		follow(t, t.tree.GetAll(), yield)
+	}
+}
+
+// GetAllContext implements bst.ContextQuerier. It yields
+// bst.ErrConcurrentModification and stops if the tree was written to while the
+// iteration was suspended. If the wrapped tree does not implement
+// bst.ContextQuerier, ctx is only checked between the values yielded by
+// GetAll.
+func (t *Tree[K, V]) GetAllContext(ctx context.Context) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		t.mu.RLock()
+		if tree, ok := t.tree.(bst.ContextQuerier[K, V]); ok {
+			guard(t, tree.GetAllContext(ctx), yield)
+			return
+		}
+		guard(t, checked(ctx, func(yield func(V, error) bool) {
+			for value := range t.tree.GetAll() {
+				if !yield(value, nil) {
+					return
+				}
+			}
+		}), yield)
+	}
+}
+
+// guard passes the items of seq on to yield, releasing the read lock of t,
+// which the caller must hold, while yield runs. It yields
+// bst.ErrConcurrentModification and stops if the tree was written to in the
+// meantime.
+func guard[K any, V any, T any](t *Tree[K, V], seq iter.Seq2[T, error], yield func(T, error) bool) {
+	version, modified := t.version, false
+	for item, err := range seq {
+		t.mu.RUnlock()
+		more := yield(item, err)
+		t.mu.RLock()
+		if !more {
+			break
+		}
+		if t.version != version {
+			modified = true
+			break
+		}
+	}
+	t.mu.RUnlock()
+	if modified {
+		var zero T
+		yield(zero, bst.ErrConcurrentModification{})
+	}
+}
+
+// follow is guard for sequences that cannot report errors: it just stops if
+// the tree was written to.
+func follow[K any, V any, T any](t *Tree[K, V], seq iter.Seq[T], yield func(T) bool) {
+	version := t.version
+	for item := range seq {
+		t.mu.RUnlock()
+		more := yield(item)
+		t.mu.RLock()
+		if !more || t.version != version {
+			break
+		}
+	}
+	t.mu.RUnlock()
+}
+
+// follow2 is follow for sequences of pairs.
+func follow2[K any, V any, A any, B any](t *Tree[K, V], seq iter.Seq2[A, B], yield func(A, B) bool) {
+	version := t.version
+	for a, b := range seq {
+		t.mu.RUnlock()
+		more := yield(a, b)
+		t.mu.RLock()
+		if !more || t.version != version {
+			break
+		}
+	}
+	t.mu.RUnlock()
+}
+
+// checked stops seq with the error of ctx once ctx is done, checking it
+// before each value.
+func checked[V any](ctx context.Context, seq iter.Seq2[V, error]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		if err := ctx.Err(); err != nil {
+			var zero V
+			yield(zero, err)
+			return
+		}
+		for value, err := range seq {
+			if !yield(value, err) {
+				return
+			}
+			if err = ctx.Err(); err != nil {
+				var zero V
+				yield(zero, err)
+				return
+			}
+		}
+	}
+}
+
+// GetMax implements bst.BST.
+func (t *Tree[K, V]) GetMax() *bst.Node[K, V] {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.GetMax()
+}
+
+// GetMin implements bst.BST.
+func (t *Tree[K, V]) GetMin() *bst.Node[K, V] {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.GetMin()
+}
+
+// GetNumberOfKeys implements bst.BST.
+func (t *Tree[K, V]) GetNumberOfKeys() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tree.GetNumberOfKeys()
+}
@@ -0,0 +1,309 @@
+package concurrent_test
+
+import (
+	"bytes"
+	"context"
+	"iter"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/avl"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/concurrent"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/unbalanced"
+	"github.com/vinicius-lino-figueiredo/bst/bsttest"
+)
+
+type BSTTestSuite struct {
+	suite.Suite
+	b bst.BST[int, int]
+}
+
+func (s *BSTTestSuite) SetupTest() {
+	s.b = concurrent.NewBST(unbalanced.NewBST(false, 0, comparer.NewComparer[int, int]()))
+	for i := range 10 {
+		s.NoError(s.b.Insert(i, i))
+	}
+}
+
+func (s *BSTTestSuite) TestQueryModified() {
+	query := bst.Query[int]{GreaterThan: &bst.Bound[int]{Value: 0, IncludeEqual: true}}
+
+	values := make([]int, 0, 10)
+	var err error
+	for value, queryErr := range s.b.Query(query) {
+		if queryErr != nil {
+			err = queryErr
+			break
+		}
+		values = append(values, value)
+		if value == 3 {
+			// writing from the loop body must not deadlock
+			s.NoError(s.b.Delete(5, nil))
+		}
+	}
+	s.Equal([]int{0, 1, 2, 3}, values)
+	s.Equal(bst.ErrConcurrentModification{}, err)
+
+	// a new iteration sees the write
+	values = values[:0]
+	for value, err := range s.b.Query(query) {
+		s.NoError(err)
+		values = append(values, value)
+	}
+	s.Equal([]int{0, 1, 2, 3, 4, 6, 7, 8, 9}, values)
+}
+
+func (s *BSTTestSuite) TestQueryFailedWrite() {
+	b := concurrent.NewBST(unbalanced.NewBST(true, 0, comparer.NewComparer[int, int]()))
+	for i := range 10 {
+		s.NoError(b.Insert(i, i))
+	}
+
+	values := make([]int, 0, 10)
+	for value, err := range b.Query(bst.Query[int]{LowerThan: &bst.Bound[int]{Value: 10}}) {
+		s.NoError(err)
+		values = append(values, value)
+		// writes that fail leave the tree, and thus the iteration, untouched
+		s.ErrorAs(b.Insert(value, 0), &bst.ErrUniqueViolated{})
+	}
+	s.Len(values, 10)
+}
+
+func (s *BSTTestSuite) TestIteratorsUnmodified() {
+	for _, b := range []bst.BST[int, int]{
+		s.b,
+		// without bst.Counter, deletions always count as writes
+		concurrent.NewBST[int, int](struct{ bst.BST[int, int] }{unbalanced.NewBST(false, 0, comparer.NewComparer[int, int]())}),
+	} {
+		if b != s.b {
+			for i := range 10 {
+				s.NoError(b.Insert(i, i))
+			}
+		}
+		value := 1000
+		values := make([]int, 0, 10)
+		for v, err := range b.Query(bst.Gte(0)) {
+			s.NoError(err)
+			values = append(values, v)
+
+			// writes that change nothing do not stop iterators
+			s.NoError(b.Update(100, 1, 2))
+			s.NoError(b.Update(5, 1, 2))
+			if _, ok := b.(bst.Counter); ok {
+				s.NoError(b.Delete(100, nil))
+				s.NoError(b.Delete(5, &value))
+			}
+		}
+		s.Len(values, 10)
+	}
+}
+
+func (s *BSTTestSuite) TestGetAllModified() {
+	values := make([]int, 0, 10)
+	for value := range s.b.GetAll() {
+		values = append(values, value)
+		if value == 3 {
+			s.NoError(s.b.Insert(100, 100))
+		}
+	}
+	s.Equal([]int{0, 1, 2, 3}, values)
+	s.Len(slices.Collect(s.b.GetAll()), 11)
+}
+
+func (s *BSTTestSuite) TestContextFallback() {
+	// embedding hides the bst.ContextQuerier methods of the wrapped tree
+	b := concurrent.NewBST[int, int](struct{ bst.BST[int, int] }{s.b}).(bst.ContextQuerier[int, int])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	scans := []iter.Seq2[int, error]{
+		b.GetAllContext(ctx),
+		b.QueryContext(ctx, bst.Query[int]{GreaterThan: &bst.Bound[int]{Value: 0, IncludeEqual: true}}),
+	}
+	for _, scan := range scans {
+		values := make([]int, 0, 10)
+		for value, err := range scan {
+			s.NoError(err)
+			values = append(values, value)
+		}
+		s.Len(values, 10)
+	}
+
+	cancel()
+	for _, scan := range scans {
+		values := make([]int, 0, 10)
+		var err error
+		for value, scanErr := range scan {
+			if scanErr != nil {
+				err = scanErr
+				break
+			}
+			values = append(values, value)
+		}
+		s.Empty(values)
+		s.ErrorIs(err, context.Canceled)
+	}
+}
+
+func (s *BSTTestSuite) TestParallel() {
+	b := concurrent.NewBST(avl.NewBST(false, 0, comparer.NewComparer[int, int]()))
+
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := range 250 {
+				s.NoError(b.Insert(w*250+i, i))
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for range 50 {
+				for value, err := range b.Query(bst.Query[int]{GreaterThan: &bst.Bound[int]{Value: 0, IncludeEqual: true}}) {
+					if err != nil {
+						s.Equal(bst.ErrConcurrentModification{}, err)
+						break
+					}
+					s.GreaterOrEqual(value, 0)
+				}
+				_, err := b.Search(w)
+				s.NoError(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	s.Equal(1000, b.GetNumberOfKeys())
+	s.Equal(0, b.GetMin().Key)
+	s.Equal(999, b.GetMax().Key)
+}
+
+func (s *BSTTestSuite) TestForwarded() {
+	_, ok := s.b.(bst.Counter)
+	s.True(ok)
+	_, ok = s.b.(bst.EntryIterator[int, int])
+	s.True(ok)
+	_, ok = s.b.(bst.OrderStatistic[int, int])
+	s.False(ok)
+	// cursors would step through nodes without the lock
+	_, ok = s.b.(bst.Navigable[int, int])
+	s.False(ok)
+
+	ordered := concurrent.NewBST(avl.NewBST(false, 0, comparer.NewComparer[int, int]()))
+	_, ok = ordered.(bst.OrderStatistic[int, int])
+	s.True(ok)
+
+	// trees missing some of the interfaces keep none of them
+	plain := concurrent.NewBST[int, int](struct {
+		bst.BST[int, int]
+		bst.Counter
+	}{s.b, s.b.(bst.Counter)})
+	_, ok = plain.(bst.Counter)
+	s.False(ok)
+	_, ok = plain.(bst.ContextQuerier[int, int])
+	s.True(ok)
+}
+
+func (s *BSTTestSuite) TestExtendedModified() {
+	b := s.b.(*concurrent.Extended[int, int])
+
+	keys := make([]int, 0, 10)
+	for key := range b.Groups() {
+		keys = append(keys, key)
+		if key == 3 {
+			s.NoError(b.Insert(100, 100))
+		}
+	}
+	s.Equal([]int{0, 1, 2, 3}, keys)
+
+	keys = keys[:0]
+	var err error
+	for group, groupErr := range b.GroupsContext(context.Background()) {
+		if groupErr != nil {
+			err = groupErr
+			break
+		}
+		keys = append(keys, group.Key)
+		if group.Key == 3 {
+			s.NoError(b.Insert(101, 101))
+		}
+	}
+	s.Equal([]int{0, 1, 2, 3}, keys)
+	s.Equal(bst.ErrConcurrentModification{}, err)
+
+	values := make([]int, 0, 10)
+	err = nil
+	for value, queryErr := range b.QueryDesc(bst.Gte(0)) {
+		if queryErr != nil {
+			err = queryErr
+			break
+		}
+		values = append(values, value)
+		s.NoError(b.Delete(value, nil))
+	}
+	s.Equal([]int{101}, values)
+	s.Equal(bst.ErrConcurrentModification{}, err)
+	s.Equal(11, b.GetNumberOfValues())
+	s.NoError(b.Validate())
+}
+
+func (s *BSTTestSuite) TestParallelExport() {
+	started, done := make(chan struct{}), make(chan struct{})
+	inserted := make(chan int)
+	// keys are inserted below the existing ones, so that a dump cut short
+	// misses the greatest key
+	go func() {
+		key := -1
+		for ; ; key-- {
+			select {
+			case <-done:
+				inserted <- -key + 9
+				return
+			default:
+				s.NoError(s.b.Insert(key, key))
+			}
+			if key == -1 {
+				close(started)
+			}
+		}
+	}()
+	<-started
+
+	for range 50 {
+		var buf bytes.Buffer
+		if err := unbalanced.EncodeJSON(&buf, s.b); err != nil {
+			s.Equal(bst.ErrConcurrentModification{}, err)
+			continue
+		}
+		// a complete dump holds every key inserted up to some point
+		decoded, err := unbalanced.DecodeJSON(&buf, true, 0, comparer.NewComparer[int, int]())
+		s.Require().NoError(err)
+		keys := decoded.GetNumberOfKeys()
+		s.GreaterOrEqual(keys, 10)
+		s.Equal(10-keys, decoded.GetMin().Key)
+		s.Equal(9, decoded.GetMax().Key)
+	}
+	close(done)
+	s.Equal(<-inserted, s.b.GetNumberOfKeys())
+}
+
+func TestBSTTestSuite(t *testing.T) {
+	suite.Run(t, new(BSTTestSuite))
+}
+
+func TestConformance(t *testing.T) {
+	bsttest.Run(t, func(unique bool, comparer bst.Comparer[string, int]) bst.BST[string, int] {
+		return concurrent.NewBST(unbalanced.NewBST(unique, 0, comparer))
+	})
+}
+
+func TestConformanceOrdered(t *testing.T) {
+	bsttest.Run(t, func(unique bool, comparer bst.Comparer[string, int]) bst.BST[string, int] {
+		return concurrent.NewBST(avl.NewBST(unique, 0, comparer))
+	})
+}
@@ -0,0 +1,180 @@
+package concurrent
+
+import (
+	"context"
+	"iter"
+	"slices"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// extendedTree lists the optional interfaces forwarded by Extended.
+type extendedTree[K any, V any] interface {
+	bst.BST[K, V]
+	bst.Counter
+	bst.Descender[K, V]
+	bst.EntryIterator[K, V]
+	bst.Nearest[K, V]
+	bst.SetQuerier[K, V]
+	bst.RangeQuerier[K, V]
+	bst.Validator
+}
+
+// Extended is a Tree that also forwards the optional interfaces of the trees
+// it wraps. Iterators behave like those of Tree: the ones reporting errors
+// yield bst.ErrConcurrentModification if the tree was written to while they
+// were suspended, and the others just stop.
+type Extended[K any, V any] struct {
+	Tree[K, V]
+	extended extendedTree[K, V]
+}
+
+// GetNumberOfValues implements bst.Counter.
+func (t *Extended[K, V]) GetNumberOfValues() int {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.GetNumberOfValues()
+}
+
+// GetAllDesc implements bst.Descender.
+func (t *Extended[K, V]) GetAllDesc() iter.Seq[V] {
+	return func(yield func(V) bool) {
+		t.mu.RLock()
+		follow(&t.Tree, t.extended.GetAllDesc(), yield)
+	}
+}
+
+// QueryDesc implements bst.Descender.
+func (t *Extended[K, V]) QueryDesc(query bst.Query[K]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		t.mu.RLock()
+		guard(&t.Tree, t.extended.QueryDesc(query), yield)
+	}
+}
+
+// All implements bst.EntryIterator.
+func (t *Extended[K, V]) All() iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		t.mu.RLock()
+		follow2(&t.Tree, t.extended.All(), yield)
+	}
+}
+
+// Groups implements bst.EntryIterator.
+func (t *Extended[K, V]) Groups() iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		t.mu.RLock()
+		follow2(&t.Tree, t.extended.Groups(), yield)
+	}
+}
+
+// GroupsContext implements bst.EntryIterator. It yields
+// bst.ErrConcurrentModification and stops if the tree was written to while the
+// iteration was suspended. The slices it yields are copies, as the loop body
+// runs without the lock.
+func (t *Extended[K, V]) GroupsContext(ctx context.Context) iter.Seq2[bst.Entry[K, []V], error] {
+	return func(yield func(bst.Entry[K, []V], error) bool) {
+		t.mu.RLock()
+		guard(&t.Tree, func(yield func(bst.Entry[K, []V], error) bool) {
+			for group, err := range t.extended.GroupsContext(ctx) {
+				group.Value = slices.Clone(group.Value)
+				if !yield(group, err) {
+					return
+				}
+			}
+		}, yield)
+	}
+}
+
+// QueryEntries implements bst.EntryIterator.
+func (t *Extended[K, V]) QueryEntries(query bst.Query[K]) iter.Seq2[bst.Entry[K, V], error] {
+	return func(yield func(bst.Entry[K, V], error) bool) {
+		t.mu.RLock()
+		guard(&t.Tree, t.extended.QueryEntries(query), yield)
+	}
+}
+
+// QueryNodes implements bst.EntryIterator.
+func (t *Extended[K, V]) QueryNodes(query bst.Query[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return func(yield func(*bst.Node[K, V], error) bool) {
+		t.mu.RLock()
+		guard(&t.Tree, t.extended.QueryNodes(query), yield)
+	}
+}
+
+// QueryIn implements bst.SetQuerier.
+func (t *Extended[K, V]) QueryIn(set bst.QuerySet[K]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		t.mu.RLock()
+		guard(&t.Tree, t.extended.QueryIn(set), yield)
+	}
+}
+
+// QueryRange implements bst.RangeQuerier.
+func (t *Extended[K, V]) QueryRange(locate bst.RangeFunc[K]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		t.mu.RLock()
+		guard(&t.Tree, t.extended.QueryRange(locate), yield)
+	}
+}
+
+// Floor implements bst.Nearest.
+func (t *Extended[K, V]) Floor(key K) (*bst.Node[K, V], bool, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.Floor(key)
+}
+
+// Ceiling implements bst.Nearest.
+func (t *Extended[K, V]) Ceiling(key K) (*bst.Node[K, V], bool, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.Ceiling(key)
+}
+
+// Predecessor implements bst.Nearest.
+func (t *Extended[K, V]) Predecessor(key K) (*bst.Node[K, V], bool, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.Predecessor(key)
+}
+
+// Successor implements bst.Nearest.
+func (t *Extended[K, V]) Successor(key K) (*bst.Node[K, V], bool, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.Successor(key)
+}
+
+// Validate implements bst.Validator.
+func (t *Extended[K, V]) Validate() error {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.Validate()
+}
+
+// Ordered is an Extended tree that also forwards bst.OrderStatistic.
+type Ordered[K any, V any] struct {
+	Extended[K, V]
+}
+
+// Rank implements bst.OrderStatistic.
+func (t *Ordered[K, V]) Rank(key K) (int, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.(bst.OrderStatistic[K, V]).Rank(key)
+}
+
+// Select implements bst.OrderStatistic.
+func (t *Ordered[K, V]) Select(k int) *bst.Node[K, V] {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.(bst.OrderStatistic[K, V]).Select(k)
+}
+
+// CountRange implements bst.OrderStatistic.
+func (t *Ordered[K, V]) CountRange(query bst.Query[K]) (int, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.extended.(bst.OrderStatistic[K, V]).CountRange(query)
+}
@@ -0,0 +1,456 @@
+// Package persistent implements bst.BST as an immutable AVL tree. Writes copy
+// the path from the root down to the nodes they change instead of modifying
+// nodes in place, so every version of the tree stays valid and Snapshot can
+// hand out a read-only view of the current one in O(1).
+//
+// Nodes may be shared by several versions, so they cannot point to their
+// parents: the Parent field of every node is nil. Nodes and their Values must
+// not be modified by callers.
+//
+// Writes must not run concurrently with each other, but reads, including
+// Snapshot, may run concurrently with writes and see the tree as it was before
+// or after each of them. Iterators keep walking the version that was current
+// when they were created.
+package persistent
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"slices"
+	"sync/atomic"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/internal/walk"
+)
+
+// Invariants verified by Root.Validate on top of the ones of bst.CheckShared.
+const (
+	InvariantHeight   bst.Invariant = "stored heights match"
+	InvariantBalanced bst.Invariant = "subtree heights differ by at most one"
+)
+
+// ErrReadOnly is returned by the writes of a snapshot.
+type ErrReadOnly struct{}
+
+func (e ErrReadOnly) Error() string {
+	return "tree is read-only"
+}
+
+// NewBST creates an empty persistent tree. If unique is set, every key holds
+// at most one value. Values are copied whenever their key changes, so unlike
+// the other adapters it takes no creation size.
+func NewBST[K any, V any](unique bool, comparer bst.Comparer[K, V]) bst.BST[K, V] {
+	r := &Root[K, V]{unique: unique, comparer: comparer}
+	r.current.Store(&version[K, V]{})
+	return r
+}
+
+// Root is the entry point of a persistent tree, or of one of its snapshots.
+type Root[K any, V any] struct {
+	current  atomic.Pointer[version[K, V]]
+	unique   bool
+	readOnly bool
+	comparer bst.Comparer[K, V]
+}
+
+// version is a state of the tree. It is never modified once stored.
+type version[K any, V any] struct {
+	root       *bst.Node[K, V]
+	nodeCount  int
+	valueCount int
+}
+
+// edit is the change in the number of keys and values made by a write.
+type edit struct {
+	keys, values int
+}
+
+// node carries the height of the subtree it roots next to the bst.Node handed
+// out to callers, as described by walk.Outer.
+type node[K any, V any] struct {
+	bst.Node[K, V]
+	height int
+}
+
+func asNode[K any, V any](n *bst.Node[K, V]) *node[K, V] {
+	return walk.Outer[node[K, V]](n)
+}
+
+func height[K any, V any](n *bst.Node[K, V]) int {
+	if n == nil {
+		return 0
+	}
+	return asNode(n).height
+}
+
+// join creates a node holding key and values above lower and greater.
+func join[K any, V any](key K, values []V, lower, greater *bst.Node[K, V]) *bst.Node[K, V] {
+	n := &node[K, V]{
+		Node:   bst.Node[K, V]{Key: key, Values: values, Lower: lower, Greater: greater},
+		height: 1 + max(height(lower), height(greater)),
+	}
+	return &n.Node
+}
+
+// balance is join for subtrees whose heights differ by up to two, rotating
+// new copies of the nodes involved when they differ by more than one.
+func balance[K any, V any](key K, values []V, lower, greater *bst.Node[K, V]) *bst.Node[K, V] {
+	switch {
+	case height(lower) > height(greater)+1:
+		if height(lower.Lower) >= height(lower.Greater) {
+			return join(lower.Key, lower.Values, lower.Lower, join(key, values, lower.Greater, greater))
+		}
+		pivot := lower.Greater
+		return join(pivot.Key, pivot.Values,
+			join(lower.Key, lower.Values, lower.Lower, pivot.Lower),
+			join(key, values, pivot.Greater, greater))
+	case height(greater) > height(lower)+1:
+		if height(greater.Greater) >= height(greater.Lower) {
+			return join(greater.Key, greater.Values, join(key, values, lower, greater.Lower), greater.Greater)
+		}
+		pivot := greater.Lower
+		return join(pivot.Key, pivot.Values,
+			join(key, values, lower, pivot.Lower),
+			join(greater.Key, greater.Values, pivot.Greater, greater.Greater))
+	}
+	return join(key, values, lower, greater)
+}
+
+// Snapshot returns a read-only view of the tree as it is now, which later
+// writes to the tree do not affect.
+func (r *Root[K, V]) Snapshot() bst.BST[K, V] {
+	s := &Root[K, V]{unique: r.unique, readOnly: true, comparer: r.comparer}
+	s.current.Store(r.current.Load())
+	return s
+}
+
+// commit makes root the current version, unless the write changed nothing.
+func (r *Root[K, V]) commit(v *version[K, V], root *bst.Node[K, V], e edit) {
+	if root == v.root {
+		return
+	}
+	r.current.Store(&version[K, V]{
+		root:       root,
+		nodeCount:  v.nodeCount + e.keys,
+		valueCount: v.valueCount + e.values,
+	})
+}
+
+// Insert implements bst.BST.
+func (r *Root[K, V]) Insert(key K, value V) error {
+	if r.readOnly {
+		return ErrReadOnly{}
+	}
+	v := r.current.Load()
+	var e edit
+	root, err := r.insert(v.root, key, value, &e)
+	if err != nil {
+		return err
+	}
+	r.commit(v, root, e)
+	return nil
+}
+
+func (r *Root[K, V]) insert(n *bst.Node[K, V], key K, value V, e *edit) (*bst.Node[K, V], error) {
+	if n == nil {
+		e.keys, e.values = 1, 1
+		return join(key, []V{value}, nil, nil), nil
+	}
+	comparison, err := r.comparer.CompareKeys(key, n.Key)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case comparison < 0:
+		lower, err := r.insert(n.Lower, key, value, e)
+		if err != nil {
+			return nil, err
+		}
+		return balance(n.Key, n.Values, lower, n.Greater), nil
+	case comparison > 0:
+		greater, err := r.insert(n.Greater, key, value, e)
+		if err != nil {
+			return nil, err
+		}
+		return balance(n.Key, n.Values, n.Lower, greater), nil
+	case r.unique:
+		return nil, bst.ErrUniqueViolated{Key: key}
+	}
+	e.values = 1
+	// clipping makes append copy values, which older versions still use
+	return join(n.Key, append(slices.Clip(n.Values), value), n.Lower, n.Greater), nil
+}
+
+// Delete implements bst.BST.
+func (r *Root[K, V]) Delete(key K, value *V) error {
+	if r.readOnly {
+		return ErrReadOnly{}
+	}
+	v := r.current.Load()
+	var e edit
+	root, err := r.delete(v.root, key, value, &e)
+	if err != nil {
+		return err
+	}
+	r.commit(v, root, e)
+	return nil
+}
+
+// delete returns n itself if nothing under it changed.
+func (r *Root[K, V]) delete(n *bst.Node[K, V], key K, value *V, e *edit) (*bst.Node[K, V], error) {
+	if n == nil {
+		return nil, nil
+	}
+	comparison, err := r.comparer.CompareKeys(key, n.Key)
+	if err != nil {
+		return n, err
+	}
+	switch {
+	case comparison < 0:
+		lower, err := r.delete(n.Lower, key, value, e)
+		if err != nil || lower == n.Lower {
+			return n, err
+		}
+		return balance(n.Key, n.Values, lower, n.Greater), nil
+	case comparison > 0:
+		greater, err := r.delete(n.Greater, key, value, e)
+		if err != nil || greater == n.Greater {
+			return n, err
+		}
+		return balance(n.Key, n.Values, n.Lower, greater), nil
+	}
+
+	if value != nil {
+		c := bst.Node[K, V]{Key: n.Key, Values: slices.Clone(n.Values)}
+		if err = walk.DeleteValue(r.comparer, &c, value); err != nil {
+			return n, err
+		}
+		switch {
+		case len(c.Values) == len(n.Values):
+			return n, nil
+		case len(c.Values) > 0:
+			e.values = len(c.Values) - len(n.Values)
+			return join(n.Key, c.Values, n.Lower, n.Greater), nil
+		}
+	}
+	e.keys, e.values = -1, -len(n.Values)
+
+	switch {
+	case n.Lower == nil:
+		return n.Greater, nil
+	case n.Greater == nil:
+		return n.Lower, nil
+	}
+	successor := walk.Min(n.Greater)
+	return balance(successor.Key, successor.Values, n.Lower, deleteMin(n.Greater)), nil
+}
+
+func deleteMin[K any, V any](n *bst.Node[K, V]) *bst.Node[K, V] {
+	if n.Lower == nil {
+		return n.Greater
+	}
+	return balance(n.Key, n.Values, deleteMin(n.Lower), n.Greater)
+}
+
+// Update implements bst.BST.
+func (r *Root[K, V]) Update(key K, old V, nw V) error {
+	if r.readOnly {
+		return ErrReadOnly{}
+	}
+	v := r.current.Load()
+	root, err := r.update(v.root, key, old, nw)
+	if err != nil {
+		return err
+	}
+	r.commit(v, root, edit{})
+	return nil
+}
+
+// update returns n itself if nothing under it changed.
+func (r *Root[K, V]) update(n *bst.Node[K, V], key K, old V, nw V) (*bst.Node[K, V], error) {
+	if n == nil {
+		return nil, nil
+	}
+	comparison, err := r.comparer.CompareKeys(key, n.Key)
+	if err != nil {
+		return n, err
+	}
+	switch {
+	case comparison < 0:
+		lower, err := r.update(n.Lower, key, old, nw)
+		if err != nil || lower == n.Lower {
+			return n, err
+		}
+		return join(n.Key, n.Values, lower, n.Greater), nil
+	case comparison > 0:
+		greater, err := r.update(n.Greater, key, old, nw)
+		if err != nil || greater == n.Greater {
+			return n, err
+		}
+		return join(n.Key, n.Values, n.Lower, greater), nil
+	}
+	c := bst.Node[K, V]{Key: n.Key, Values: slices.Clone(n.Values)}
+	replaced, err := walk.ReplaceValue(r.comparer, &c, old, nw)
+	if err != nil || !replaced {
+		return n, err
+	}
+	return join(n.Key, c.Values, n.Lower, n.Greater), nil
+}
+
+// Search implements bst.BST.
+func (r *Root[K, V]) Search(key K) (*bst.Node[K, V], error) {
+	return walk.Search(r.comparer, r.current.Load().root, key)
+}
+
+// Query implements bst.BST.
+func (r *Root[K, V]) Query(query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.Query(r.comparer, r.current.Load().root, query)
+}
+
+// GetAll implements bst.BST.
+func (r *Root[K, V]) GetAll() iter.Seq[V] {
+	return walk.Values(r.current.Load().root)
+}
+
+// All implements bst.EntryIterator.
+func (r *Root[K, V]) All() iter.Seq2[K, V] {
+	return walk.Entries(r.current.Load().root)
+}
+
+// Groups implements bst.EntryIterator.
+func (r *Root[K, V]) Groups() iter.Seq2[K, []V] {
+	return walk.Groups(r.current.Load().root)
+}
+
+// GroupsContext implements bst.EntryIterator.
+func (r *Root[K, V]) GroupsContext(ctx context.Context) iter.Seq2[bst.Entry[K, []V], error] {
+	return walk.GroupsContext(ctx, r.current.Load().root)
+}
+
+// QueryEntries implements bst.EntryIterator.
+func (r *Root[K, V]) QueryEntries(query bst.Query[K]) iter.Seq2[bst.Entry[K, V], error] {
+	return walk.QueryEntries(r.comparer, r.current.Load().root, query)
+}
+
+// QueryNodes implements bst.EntryIterator.
+func (r *Root[K, V]) QueryNodes(query bst.Query[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return walk.QueryNodes(r.comparer, r.current.Load().root, query)
+}
+
+// QueryIn implements bst.SetQuerier.
+func (r *Root[K, V]) QueryIn(set bst.QuerySet[K]) iter.Seq2[V, error] {
+	return walk.QueryIn(r.comparer, r.current.Load().root, set)
+}
+
+// QueryRange implements bst.RangeQuerier.
+func (r *Root[K, V]) QueryRange(locate bst.RangeFunc[K]) iter.Seq2[V, error] {
+	return walk.QueryRange(r.current.Load().root, locate)
+}
+
+// QueryContext implements bst.ContextQuerier.
+func (r *Root[K, V]) QueryContext(ctx context.Context, query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.QueryContext(ctx, r.comparer, r.current.Load().root, query)
+}
+
+// GetAllContext implements bst.ContextQuerier.
+func (r *Root[K, V]) GetAllContext(ctx context.Context) iter.Seq2[V, error] {
+	return walk.ValuesContext(ctx, r.current.Load().root)
+}
+
+// GetAllDesc implements bst.Descender.
+func (r *Root[K, V]) GetAllDesc() iter.Seq[V] {
+	return walk.ValuesDesc(r.current.Load().root)
+}
+
+// QueryDesc implements bst.Descender.
+func (r *Root[K, V]) QueryDesc(query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.QueryDesc(r.comparer, r.current.Load().root, query)
+}
+
+// Floor implements bst.Nearest.
+func (r *Root[K, V]) Floor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Floor(r.comparer, r.current.Load().root, key)
+}
+
+// Ceiling implements bst.Nearest.
+func (r *Root[K, V]) Ceiling(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Ceiling(r.comparer, r.current.Load().root, key)
+}
+
+// Predecessor implements bst.Nearest.
+func (r *Root[K, V]) Predecessor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Predecessor(r.comparer, r.current.Load().root, key)
+}
+
+// Successor implements bst.Nearest.
+func (r *Root[K, V]) Successor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Successor(r.comparer, r.current.Load().root, key)
+}
+
+// GetMax implements bst.BST.
+func (r *Root[K, V]) GetMax() *bst.Node[K, V] {
+	root := r.current.Load().root
+	if root == nil {
+		return nil
+	}
+	return walk.Max(root)
+}
+
+// GetMin implements bst.BST.
+func (r *Root[K, V]) GetMin() *bst.Node[K, V] {
+	root := r.current.Load().root
+	if root == nil {
+		return nil
+	}
+	return walk.Min(root)
+}
+
+// GetNumberOfKeys implements bst.BST.
+func (r *Root[K, V]) GetNumberOfKeys() int {
+	return r.current.Load().nodeCount
+}
+
+// GetNumberOfValues implements bst.Counter.
+func (r *Root[K, V]) GetNumberOfValues() int {
+	return r.current.Load().valueCount
+}
+
+// Height returns the number of nodes on the longest path from the root to a
+// leaf.
+func (r *Root[K, V]) Height() int {
+	return height(r.current.Load().root)
+}
+
+// Validate checks the structure of the tree with bst.CheckShared, then checks
+// that every node knows its height and is balanced.
+func (r *Root[K, V]) Validate() error {
+	v := r.current.Load()
+	if err := bst.CheckShared(v.root, r.comparer, r.unique, v.nodeCount, v.valueCount); err != nil {
+		return err
+	}
+	_, err := checkHeight(v.root)
+	return err
+}
+
+func checkHeight[K any, V any](n *bst.Node[K, V]) (int, error) {
+	if n == nil {
+		return 0, nil
+	}
+	lower, err := checkHeight(n.Lower)
+	if err != nil {
+		return 0, err
+	}
+	greater, err := checkHeight(n.Greater)
+	if err != nil {
+		return 0, err
+	}
+	actual := 1 + max(lower, greater)
+	switch {
+	case asNode(n).height != actual:
+		return 0, bst.ErrInvalidTree{Key: n.Key, Invariant: InvariantHeight, Detail: fmt.Sprintf("stored %d, actual %d", asNode(n).height, actual)}
+	case lower-greater > 1 || greater-lower > 1:
+		return 0, bst.ErrInvalidTree{Key: n.Key, Invariant: InvariantBalanced, Detail: fmt.Sprintf("heights %d and %d", lower, greater)}
+	}
+	return actual, nil
+}
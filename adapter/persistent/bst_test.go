@@ -0,0 +1,164 @@
+package persistent_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/persistent"
+	"github.com/vinicius-lino-figueiredo/bst/bsttest"
+)
+
+type BSTTestSuite struct {
+	suite.Suite
+}
+
+func (s *BSTTestSuite) TestSnapshot() {
+	b := persistent.NewBST(false, comparer.NewComparer[int, int]()).(*persistent.Root[int, int])
+	for i := range 100 {
+		s.NoError(b.Insert(i, i))
+	}
+
+	snapshot := b.Snapshot()
+	s.NoError(b.Insert(50, -50))
+	s.NoError(b.Update(10, 10, -10))
+	s.NoError(b.Delete(20, nil))
+	s.NoError(b.Insert(100, 100))
+	s.NoError(b.Validate())
+
+	// the snapshot still sees the tree as it was
+	s.NoError(snapshot.(bst.Validator).Validate())
+	s.Equal(100, snapshot.GetNumberOfKeys())
+	s.Equal(100, snapshot.(bst.Counter).GetNumberOfValues())
+	s.Equal(99, snapshot.GetMax().Key)
+	for i := range 100 {
+		node, err := snapshot.Search(i)
+		s.NoError(err)
+		s.Equal([]int{i}, node.Values)
+	}
+
+	// while the tree moved on
+	s.Equal(100, b.GetNumberOfKeys())
+	s.Equal(101, b.GetNumberOfValues())
+	node, err := b.Search(50)
+	s.NoError(err)
+	s.Equal([]int{50, -50}, node.Values)
+	node, err = b.Search(10)
+	s.NoError(err)
+	s.Equal([]int{-10}, node.Values)
+	node, err = b.Search(20)
+	s.NoError(err)
+	s.Nil(node)
+}
+
+func (s *BSTTestSuite) TestUnchangedVersion() {
+	b := persistent.NewBST(false, comparer.NewComparer[int, int]())
+	for i := range 10 {
+		s.NoError(b.Insert(i, i))
+	}
+	node, err := b.Search(5)
+	s.NoError(err)
+
+	// writes that change nothing keep the nodes of the current version
+	value := 1
+	s.NoError(b.Update(5, 1, 2))
+	s.NoError(b.Update(50, 5, 2))
+	s.NoError(b.Delete(5, &value))
+	s.NoError(b.Delete(50, nil))
+	again, err := b.Search(5)
+	s.NoError(err)
+	s.Same(node, again)
+}
+
+func (s *BSTTestSuite) TestSnapshotReadOnly() {
+	b := persistent.NewBST(false, comparer.NewComparer[int, int]())
+	s.NoError(b.Insert(1, 1))
+	snapshot := b.(*persistent.Root[int, int]).Snapshot()
+
+	value := 1
+	s.Equal(persistent.ErrReadOnly{}, snapshot.Insert(2, 2))
+	s.Equal(persistent.ErrReadOnly{}, snapshot.Update(1, 1, 2))
+	s.Equal(persistent.ErrReadOnly{}, snapshot.Delete(1, &value))
+	s.Equal(1, snapshot.GetNumberOfKeys())
+
+	// snapshots of snapshots work too
+	again := snapshot.(*persistent.Root[int, int]).Snapshot()
+	s.Equal(1, again.GetMin().Key)
+}
+
+func (s *BSTTestSuite) TestQueryVersion() {
+	b := persistent.NewBST(true, comparer.NewComparer[int, int]())
+	for i := range 10 {
+		s.NoError(b.Insert(i, i))
+	}
+
+	// an iterator keeps walking the version it was created on
+	values := make([]int, 0, 10)
+	for value, err := range b.Query(bst.Query[int]{GreaterThan: &bst.Bound[int]{Value: 0, IncludeEqual: true}}) {
+		s.NoError(err)
+		values = append(values, value)
+		s.NoError(b.Delete(9-value, nil))
+	}
+	s.Equal([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}, values)
+	s.Zero(b.GetNumberOfKeys())
+}
+
+func (s *BSTTestSuite) TestConcurrentReads() {
+	b := persistent.NewBST(false, comparer.NewComparer[int, int]()).(*persistent.Root[int, int])
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				snapshot := b.Snapshot()
+				count := 0
+				for range snapshot.GetAll() {
+					count++
+				}
+				s.Equal(snapshot.GetNumberOfKeys(), count)
+			}
+		}()
+	}
+	for i := range 1000 {
+		s.NoError(b.Insert(i, i))
+	}
+	wg.Wait()
+	s.NoError(b.Validate())
+}
+
+func (s *BSTTestSuite) TestValidate() {
+	b := persistent.NewBST(false, comparer.NewComparer[int, int]()).(*persistent.Root[int, int])
+	for i := range 100 {
+		s.NoError(b.Insert(i, i))
+	}
+	s.NoError(b.Validate())
+
+	var invalid bst.ErrInvalidTree
+
+	root, _ := b.Search(63)
+	lowest := b.GetMin()
+	lowest.Parent = root
+	s.ErrorAs(b.Validate(), &invalid)
+	s.Equal(bst.InvariantParent, invalid.Invariant)
+	lowest.Parent = nil
+
+	lowest.Key = 1000
+	s.ErrorAs(b.Validate(), &invalid)
+	s.Equal(bst.InvariantOrder, invalid.Invariant)
+	lowest.Key = 0
+	s.NoError(b.Validate())
+}
+
+func TestBSTTestSuite(t *testing.T) {
+	suite.Run(t, new(BSTTestSuite))
+}
+
+func TestConformance(t *testing.T) {
+	bsttest.Run(t, func(unique bool, comparer bst.Comparer[string, int]) bst.BST[string, int] {
+		return persistent.NewBST(unique, comparer)
+	})
+}
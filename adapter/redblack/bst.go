@@ -0,0 +1,457 @@
+// Package redblack implements bst.BST as a red-black tree. It needs at most
+// two rotations per Insert and three per Delete, trading slightly taller trees
+// than AVL for cheaper writes.
+package redblack
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/internal/walk"
+)
+
+// Invariants verified by Root.Validate on top of the ones of bst.CheckTree.
+const (
+	InvariantBlackRoot   bst.Invariant = "root is black"
+	InvariantRedChildren bst.Invariant = "red nodes have black children"
+	InvariantBlackHeight bst.Invariant = "paths cross as many black nodes"
+)
+
+// NewBST creates an empty red-black tree. The arguments have the same meaning
+// as in unbalanced.NewBST.
+func NewBST[K any, V any](unique bool, creationSize int, comparer bst.Comparer[K, V]) bst.BST[K, V] {
+	if unique {
+		creationSize = 1
+	} else if creationSize <= 0 {
+		creationSize = 8
+	}
+	return &Root[K, V]{
+		unique:       unique,
+		creationSize: creationSize,
+		comparer:     comparer,
+		nodePool:     sync.Pool{New: func() any { return &node[K, V]{} }},
+	}
+}
+
+// Root is the entry point of a red-black tree.
+type Root[K any, V any] struct {
+	root         *bst.Node[K, V]
+	nodeCount    int
+	valueCount   int
+	unique       bool
+	creationSize int
+	nodePool     sync.Pool
+	comparer     bst.Comparer[K, V]
+}
+
+// node keeps the color next to the bst.Node handed out to callers, as
+// described by walk.Outer, so that bst.Node does not need to know about it.
+type node[K any, V any] struct {
+	bst.Node[K, V]
+	red bool
+}
+
+func asNode[K any, V any](n *bst.Node[K, V]) *node[K, V] {
+	return walk.Outer[node[K, V]](n)
+}
+
+// isRed reports whether n is red. Missing children count as black.
+func isRed[K any, V any](n *bst.Node[K, V]) bool {
+	return n != nil && asNode(n).red
+}
+
+func setRed[K any, V any](n *bst.Node[K, V], red bool) {
+	asNode(n).red = red
+}
+
+// Insert implements bst.BST.
+func (r *Root[K, V]) Insert(key K, value V) error {
+	if r.root == nil {
+		r.root = r.createEmptyNode(key, nil)
+		r.root.Values = append(r.root.Values, value)
+		setRed(r.root, false)
+		r.nodeCount++
+		r.valueCount++
+		return nil
+	}
+	node := r.root
+	for {
+		comparison, err := r.comparer.CompareKeys(key, node.Key)
+		if err != nil {
+			return err
+		}
+		switch {
+		case comparison > 0:
+			if node.Greater == nil {
+				node.Greater = r.createEmptyNode(key, node)
+				node.Greater.Values = append(node.Greater.Values, value)
+				r.nodeCount++
+				r.valueCount++
+				r.fixInsert(node.Greater)
+				return nil
+			}
+			node = node.Greater
+		case comparison < 0:
+			if node.Lower == nil {
+				node.Lower = r.createEmptyNode(key, node)
+				node.Lower.Values = append(node.Lower.Values, value)
+				r.nodeCount++
+				r.valueCount++
+				r.fixInsert(node.Lower)
+				return nil
+			}
+			node = node.Lower
+		default:
+			if r.unique {
+				return bst.ErrUniqueViolated{Key: key}
+			}
+			node.Values = append(node.Values, value)
+			r.valueCount++
+			return nil
+		}
+	}
+}
+
+func (r *Root[K, V]) createEmptyNode(key K, parent *bst.Node[K, V]) *bst.Node[K, V] {
+	n := r.nodePool.Get().(*node[K, V])
+	n.Key = key
+	n.Values = make([]V, 0, r.creationSize)
+	n.Parent = parent
+	n.red = true
+	return &n.Node
+}
+
+func (r *Root[K, V]) releaseNode(n *bst.Node[K, V]) {
+	n.Parent, n.Lower, n.Greater = nil, nil, nil
+	n.Values = nil
+	r.nodePool.Put(asNode(n))
+}
+
+// fixInsert restores the red-black properties after the red node n was
+// attached as a leaf.
+func (r *Root[K, V]) fixInsert(n *bst.Node[K, V]) {
+	for isRed(n.Parent) {
+		parent := n.Parent
+		grandparent := parent.Parent
+		if parent == grandparent.Lower {
+			if uncle := grandparent.Greater; isRed(uncle) {
+				setRed(parent, false)
+				setRed(uncle, false)
+				setRed(grandparent, true)
+				n = grandparent
+				continue
+			}
+			if n == parent.Greater {
+				r.rotateLeft(parent)
+				n, parent = parent, n
+			}
+			setRed(parent, false)
+			setRed(grandparent, true)
+			r.rotateRight(grandparent)
+		} else {
+			if uncle := grandparent.Lower; isRed(uncle) {
+				setRed(parent, false)
+				setRed(uncle, false)
+				setRed(grandparent, true)
+				n = grandparent
+				continue
+			}
+			if n == parent.Lower {
+				r.rotateRight(parent)
+				n, parent = parent, n
+			}
+			setRed(parent, false)
+			setRed(grandparent, true)
+			r.rotateLeft(grandparent)
+		}
+	}
+	setRed(r.root, false)
+}
+
+func (r *Root[K, V]) rotateLeft(n *bst.Node[K, V]) {
+	pivot := n.Greater
+	n.Greater = pivot.Lower
+	if n.Greater != nil {
+		n.Greater.Parent = n
+	}
+	r.replaceChild(n.Parent, n, pivot)
+	pivot.Lower = n
+	n.Parent = pivot
+}
+
+func (r *Root[K, V]) rotateRight(n *bst.Node[K, V]) {
+	pivot := n.Lower
+	n.Lower = pivot.Greater
+	if n.Lower != nil {
+		n.Lower.Parent = n
+	}
+	r.replaceChild(n.Parent, n, pivot)
+	pivot.Greater = n
+	n.Parent = pivot
+}
+
+// replaceChild links child where old used to hang from parent.
+func (r *Root[K, V]) replaceChild(parent, old, child *bst.Node[K, V]) {
+	switch {
+	case parent == nil:
+		r.root = child
+	case parent.Lower == old:
+		parent.Lower = child
+	default:
+		parent.Greater = child
+	}
+	if child != nil {
+		child.Parent = parent
+	}
+}
+
+// Search implements bst.BST.
+func (r *Root[K, V]) Search(key K) (*bst.Node[K, V], error) {
+	return walk.Search(r.comparer, r.root, key)
+}
+
+// Query implements bst.BST.
+func (r *Root[K, V]) Query(query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.Query(r.comparer, r.root, query)
+}
+
+// Delete implements bst.BST.
+func (r *Root[K, V]) Delete(key K, value *V) error {
+	node, removed, err := walk.Remove(r.comparer, r.root, key, value)
+	r.valueCount -= removed
+	if err != nil || node == nil {
+		return err
+	}
+	r.nodeCount--
+
+	child := node.Lower
+	if child == nil {
+		child = node.Greater
+	}
+	parent := node.Parent
+	r.replaceChild(parent, node, child)
+	if !isRed(node) {
+		r.fixDelete(child, parent)
+	}
+	r.releaseNode(node)
+	return nil
+}
+
+// fixDelete restores the red-black properties after a black node was removed
+// from under parent, leaving n (possibly nil) one black node short.
+func (r *Root[K, V]) fixDelete(n, parent *bst.Node[K, V]) {
+	for n != r.root && !isRed(n) {
+		if n == parent.Lower {
+			sibling := parent.Greater
+			if isRed(sibling) {
+				setRed(sibling, false)
+				setRed(parent, true)
+				r.rotateLeft(parent)
+				sibling = parent.Greater
+			}
+			if !isRed(sibling.Lower) && !isRed(sibling.Greater) {
+				setRed(sibling, true)
+				n, parent = parent, parent.Parent
+				continue
+			}
+			if !isRed(sibling.Greater) {
+				setRed(sibling.Lower, false)
+				setRed(sibling, true)
+				r.rotateRight(sibling)
+				sibling = parent.Greater
+			}
+			setRed(sibling, isRed(parent))
+			setRed(parent, false)
+			setRed(sibling.Greater, false)
+			r.rotateLeft(parent)
+		} else {
+			sibling := parent.Lower
+			if isRed(sibling) {
+				setRed(sibling, false)
+				setRed(parent, true)
+				r.rotateRight(parent)
+				sibling = parent.Lower
+			}
+			if !isRed(sibling.Lower) && !isRed(sibling.Greater) {
+				setRed(sibling, true)
+				n, parent = parent, parent.Parent
+				continue
+			}
+			if !isRed(sibling.Lower) {
+				setRed(sibling.Greater, false)
+				setRed(sibling, true)
+				r.rotateLeft(sibling)
+				sibling = parent.Lower
+			}
+			setRed(sibling, isRed(parent))
+			setRed(parent, false)
+			setRed(sibling.Lower, false)
+			r.rotateRight(parent)
+		}
+		n = r.root
+	}
+	if n != nil {
+		setRed(n, false)
+	}
+}
+
+// GetAll implements bst.BST.
+func (r *Root[K, V]) GetAll() iter.Seq[V] {
+	return walk.Values(r.root)
+}
+
+// All implements bst.EntryIterator.
+func (r *Root[K, V]) All() iter.Seq2[K, V] {
+	return walk.Entries(r.root)
+}
+
+// Groups implements bst.EntryIterator.
+func (r *Root[K, V]) Groups() iter.Seq2[K, []V] {
+	return walk.Groups(r.root)
+}
+
+// GroupsContext implements bst.EntryIterator.
+func (r *Root[K, V]) GroupsContext(ctx context.Context) iter.Seq2[bst.Entry[K, []V], error] {
+	return walk.GroupsContext(ctx, r.root)
+}
+
+// QueryEntries implements bst.EntryIterator.
+func (r *Root[K, V]) QueryEntries(query bst.Query[K]) iter.Seq2[bst.Entry[K, V], error] {
+	return walk.QueryEntries(r.comparer, r.root, query)
+}
+
+// QueryNodes implements bst.EntryIterator.
+func (r *Root[K, V]) QueryNodes(query bst.Query[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return walk.QueryNodes(r.comparer, r.root, query)
+}
+
+// QueryIn implements bst.SetQuerier.
+func (r *Root[K, V]) QueryIn(set bst.QuerySet[K]) iter.Seq2[V, error] {
+	return walk.QueryIn(r.comparer, r.root, set)
+}
+
+// QueryRange implements bst.RangeQuerier.
+func (r *Root[K, V]) QueryRange(locate bst.RangeFunc[K]) iter.Seq2[V, error] {
+	return walk.QueryRange(r.root, locate)
+}
+
+// QueryContext implements bst.ContextQuerier.
+func (r *Root[K, V]) QueryContext(ctx context.Context, query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.QueryContext(ctx, r.comparer, r.root, query)
+}
+
+// GetAllContext implements bst.ContextQuerier.
+func (r *Root[K, V]) GetAllContext(ctx context.Context) iter.Seq2[V, error] {
+	return walk.ValuesContext(ctx, r.root)
+}
+
+// GetAllDesc implements bst.Descender.
+func (r *Root[K, V]) GetAllDesc() iter.Seq[V] {
+	return walk.ValuesDesc(r.root)
+}
+
+// QueryDesc implements bst.Descender.
+func (r *Root[K, V]) QueryDesc(query bst.Query[K]) iter.Seq2[V, error] {
+	return walk.QueryDesc(r.comparer, r.root, query)
+}
+
+// Cursor implements bst.Navigable.
+func (r *Root[K, V]) Cursor() *bst.Cursor[K, V] {
+	return bst.NewCursor(func() *bst.Node[K, V] { return r.root }, r.comparer)
+}
+
+// Floor implements bst.Nearest.
+func (r *Root[K, V]) Floor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Floor(r.comparer, r.root, key)
+}
+
+// Ceiling implements bst.Nearest.
+func (r *Root[K, V]) Ceiling(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Ceiling(r.comparer, r.root, key)
+}
+
+// Predecessor implements bst.Nearest.
+func (r *Root[K, V]) Predecessor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Predecessor(r.comparer, r.root, key)
+}
+
+// Successor implements bst.Nearest.
+func (r *Root[K, V]) Successor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Successor(r.comparer, r.root, key)
+}
+
+// GetMax implements bst.BST.
+func (r *Root[K, V]) GetMax() *bst.Node[K, V] {
+	if r.root == nil {
+		return nil
+	}
+	return walk.Max(r.root)
+}
+
+// GetMin implements bst.BST.
+func (r *Root[K, V]) GetMin() *bst.Node[K, V] {
+	if r.root == nil {
+		return nil
+	}
+	return walk.Min(r.root)
+}
+
+// GetNumberOfKeys implements bst.BST.
+func (r *Root[K, V]) GetNumberOfKeys() int {
+	return r.nodeCount
+}
+
+// GetNumberOfValues implements bst.Counter.
+func (r *Root[K, V]) GetNumberOfValues() int {
+	return r.valueCount
+}
+
+// Validate checks the structure of the tree with bst.CheckTree, then checks
+// the coloring rules.
+func (r *Root[K, V]) Validate() error {
+	if err := bst.CheckTree(r.root, r.comparer, r.unique, r.nodeCount, r.valueCount); err != nil {
+		return err
+	}
+	if isRed(r.root) {
+		return bst.ErrInvalidTree{Key: r.root.Key, Invariant: InvariantBlackRoot, Detail: "root is red"}
+	}
+	_, err := checkBlackHeight(r.root)
+	return err
+}
+
+func checkBlackHeight[K any, V any](n *bst.Node[K, V]) (int, error) {
+	if n == nil {
+		return 1, nil
+	}
+	if isRed(n) && (isRed(n.Lower) || isRed(n.Greater)) {
+		return 0, bst.ErrInvalidTree{Key: n.Key, Invariant: InvariantRedChildren, Detail: "red node has a red child"}
+	}
+	lower, err := checkBlackHeight(n.Lower)
+	if err != nil {
+		return 0, err
+	}
+	greater, err := checkBlackHeight(n.Greater)
+	if err != nil {
+		return 0, err
+	}
+	if lower != greater {
+		return 0, bst.ErrInvalidTree{Key: n.Key, Invariant: InvariantBlackHeight, Detail: fmt.Sprintf("black heights %d and %d", lower, greater)}
+	}
+	if isRed(n) {
+		return lower, nil
+	}
+	return lower + 1, nil
+}
+
+// Update implements bst.BST.
+func (r *Root[K, V]) Update(key K, old V, nw V) error {
+	node, err := r.Search(key)
+	if err != nil || node == nil {
+		return err
+	}
+	_, err = walk.ReplaceValue(r.comparer, node, old, nw)
+	return err
+}
@@ -0,0 +1,50 @@
+package redblack_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/redblack"
+	"github.com/vinicius-lino-figueiredo/bst/bsttest"
+)
+
+type BSTTestSuite struct {
+	suite.Suite
+}
+
+func (s *BSTTestSuite) TestValidate() {
+	b := redblack.NewBST(false, 0, comparer.NewComparer[int, int]()).(*redblack.Root[int, int])
+	s.NoError(b.Validate())
+	for i := range 100 {
+		s.NoError(b.Insert(i, i))
+	}
+	s.NoError(b.Validate())
+
+	var invalid bst.ErrInvalidTree
+
+	lowest, highest := b.GetMin(), b.GetMax()
+	lowest.Key, highest.Key = highest.Key, lowest.Key
+	s.ErrorAs(b.Validate(), &invalid)
+	s.Equal(bst.InvariantOrder, invalid.Invariant)
+	lowest.Key, highest.Key = highest.Key, lowest.Key
+
+	values := lowest.Values
+	lowest.Values = nil
+	s.ErrorAs(b.Validate(), &invalid)
+	s.Equal(bst.InvariantValues, invalid.Invariant)
+	s.Equal(0, invalid.Key)
+	lowest.Values = values
+	s.NoError(b.Validate())
+}
+
+func TestBSTTestSuite(t *testing.T) {
+	suite.Run(t, new(BSTTestSuite))
+}
+
+func TestConformance(t *testing.T) {
+	bsttest.Run(t, func(unique bool, comparer bst.Comparer[string, int]) bst.BST[string, int] {
+		return redblack.NewBST(unique, 0, comparer)
+	})
+}
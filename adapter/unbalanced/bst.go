@@ -2,12 +2,13 @@
 package unbalanced
 
 import (
+	"context"
 	"iter"
 	"math/rand"
-	"slices"
 	"sync"
 
 	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/internal/walk"
 )
 
 // NewBST TODO
@@ -31,12 +32,16 @@ func NewBST[K any, V any](unique bool, creationSize int, comparer bst.Comparer[K
 // Root TODO.
 type Root[K any, V any] struct {
 	bst.Node[K, V]
-	initialized  bool
-	nodeCount    int
-	unique       bool
-	creationSize int
-	nodePool     sync.Pool
-	comparer     bst.Comparer[K, V]
+	initialized bool
+	nodeCount   int
+	valueCount  int
+	// modifications counts the writes made to the tree, letting iterators
+	// notice writes made while they were suspended.
+	modifications uint64
+	unique        bool
+	creationSize  int
+	nodePool      sync.Pool
+	comparer      bst.Comparer[K, V]
 }
 
 // Insert implements bst.BST.
@@ -46,6 +51,8 @@ func (r *Root[K, V]) Insert(key K, value V) error {
 		r.initialized = true
 		r.Values = append(r.Values, value)
 		r.nodeCount++
+		r.valueCount++
+		r.modifications++
 		return nil
 	}
 	node := &r.Node
@@ -80,6 +87,8 @@ Loop:
 		}
 	}
 	node.Values = append(node.Values, value)
+	r.valueCount++
+	r.modifications++
 	return nil
 }
 
@@ -93,199 +102,19 @@ func (r *Root[K, V]) createEmptyNode(key K, parent *bst.Node[K, V]) *bst.Node[K,
 
 // Search implements bst.BST.
 func (r *Root[K, V]) Search(key K) (*bst.Node[K, V], error) {
-	if !r.initialized {
-		return nil, nil
-	}
-	node := &r.Node
-	for {
-		comparison, err := r.comparer.CompareKeys(key, node.Key)
-		if err != nil {
-			return nil, err
-		}
-		switch {
-		case comparison > 0 && node.Greater != nil:
-			node = node.Greater
-			continue
-		case comparison < 0 && node.Lower != nil:
-			node = node.Lower
-			continue
-		case comparison == 0:
-			return node, nil
-		}
-		return nil, nil
-	}
+	return walk.Search(r.comparer, r.root(), key)
 }
 
 // Query implements bst.BST.
 func (r *Root[K, V]) Query(query bst.Query[K]) iter.Seq2[V, error] {
-	return func(yield func(V, error) bool) {
-		switch {
-		case query.GreaterThan != nil:
-			switch query.LowerThan {
-			case nil:
-				_ = r.queryGreater(&r.Node, query.GreaterThan, yield)
-			default:
-				_ = r.doubleQuery(&r.Node, query, yield)
-			}
-		case query.LowerThan != nil:
-			_ = r.queryLower(&r.Node, query.LowerThan, yield)
-		default:
-		}
-	}
-}
-
-func (r *Root[K, V]) doubleQuery(node *bst.Node[K, V], query bst.Query[K], yield func(V, error) bool) bool {
-	ltComp, err := r.comparer.CompareKeys(node.Key, query.LowerThan.Value)
-	if err != nil {
-		yield(*new(V), err)
-		return false
-	}
-
-	switch {
-	case ltComp > 0:
-		return r.treatAboveMax(node, query, yield)
-	case ltComp < 0:
-		return r.treatBelowMax(node, query, yield)
-	default:
-		return r.treatEqualMax(node, query, yield)
-	}
+	return guardErr(r, walk.Query(r.comparer, r.root(), query))
 }
 
-func (r *Root[K, V]) treatAboveMax(node *bst.Node[K, V], query bst.Query[K], yield func(V, error) bool) bool {
-	if node.Lower == nil {
-		return true
-	}
-	gtComp, err := r.comparer.CompareKeys(node.Key, query.GreaterThan.Value)
-	if err != nil {
-		yield(*new(V), err)
-		return false
-	}
-
-	if gtComp < 0 {
-		return true
-	}
-
-	return r.doubleQuery(node.Lower, query, yield)
-}
-
-func (r *Root[K, V]) treatBelowMax(node *bst.Node[K, V], query bst.Query[K], yield func(V, error) bool) bool {
-	gtComp, err := r.comparer.CompareKeys(node.Key, query.GreaterThan.Value)
-	if err != nil {
-		yield(*new(V), err)
-		return false
-	}
-	switch {
-	case gtComp < 0: // node lower than min
-		if r.Greater != nil {
-			return r.doubleQuery(node.Greater, query, yield)
-		}
-	case gtComp == 0: // node equal to min
-		if query.GreaterThan.IncludeEqual && !r.yieldValues(node, yield) {
-			return false
-		}
-	default:
-		if node.Lower != nil && !r.queryGreater(node.Lower, query.GreaterThan, yield) {
-			return false
-		}
-		if !r.yieldValues(node, yield) {
-			return false
-		}
-	}
-	if node.Greater != nil {
-		return r.doubleQuery(node.Greater, query, yield)
-	}
-	return true
-}
-
-func (r *Root[K, V]) treatEqualMax(node *bst.Node[K, V], query bst.Query[K], yield func(V, error) bool) bool {
-	gtComp, err := r.comparer.CompareKeys(node.Key, query.GreaterThan.Value)
-	if err != nil {
-		yield(*new(V), err)
-		return false
-	}
-	switch {
-	case gtComp > 0:
-		if node.Lower != nil && !r.queryGreater(node.Lower, query.GreaterThan, yield) {
-			return false
-		}
-		if query.LowerThan.IncludeEqual {
-			return r.yieldValues(node, yield)
-		}
-	case gtComp < 0:
-	default:
-		if query.GreaterThan.IncludeEqual && query.LowerThan.IncludeEqual {
-			return r.yieldValues(node, yield)
-		}
-	}
-	return true
-}
-
-func (r *Root[K, V]) queryGreater(node *bst.Node[K, V], bound *bst.Bound[K], yield func(V, error) bool) bool {
-	comp, err := r.comparer.CompareKeys(node.Key, bound.Value)
-	if err != nil {
-		yield(*new(V), err)
-		return false
-	}
-	switch {
-	case comp > 0:
-		if node.Lower != nil && !r.queryGreater(node.Lower, bound, yield) {
-			return false
-		}
-		if !r.yieldValues(node, yield) {
-			return false
-		}
-	case comp < 0:
-	default:
-		if bound.IncludeEqual && !r.yieldValues(node, yield) {
-			return false
-		}
-	}
-	if node.Greater != nil {
-		return r.queryGreater(node.Greater, bound, yield)
-	}
-	return true
-}
-
-func (r *Root[K, V]) queryLower(node *bst.Node[K, V], bound *bst.Bound[K], yield func(V, error) bool) bool {
-	comp, err := r.comparer.CompareKeys(node.Key, bound.Value)
-	if err != nil {
-		yield(*new(V), err)
-		return false
-	}
-
-	switch {
-	case comp < 0:
-		if node.Lower != nil && !r.queryLower(node.Lower, bound, yield) {
-			return false
-		}
-		if !r.yieldValues(node, yield) {
-			return false
-		}
-		if node.Greater != nil && !r.queryLower(node.Greater, bound, yield) {
-			return false
-		}
-	case comp > 0:
-		if node.Lower != nil {
-			return r.queryLower(node.Lower, bound, yield)
-		}
-	default:
-		if node.Lower != nil && !r.queryLower(node.Lower, bound, yield) {
-			return false
-		}
-		if bound.IncludeEqual && !r.yieldValues(node, yield) {
-			return false
-		}
-	}
-	return true
-}
-
-func (r *Root[K, V]) yieldValues(node *bst.Node[K, V], yield func(V, error) bool) bool {
-	for _, v := range node.Values {
-		if !yield(v, nil) {
-			return false
-		}
+func (r *Root[K, V]) root() *bst.Node[K, V] {
+	if !r.initialized {
+		return nil
 	}
-	return true
+	return &r.Node
 }
 
 // Delete implements bst.BST.
@@ -298,11 +127,19 @@ func (r *Root[K, V]) Delete(key K, value *V) error {
 		return err
 	}
 	if value != nil {
-		if err = r.deleteValue(node, value); err != nil || len(node.Values) > 0 {
+		n := len(node.Values)
+		err = walk.DeleteValue(r.comparer, node, value)
+		if len(node.Values) < n {
+			r.valueCount -= n - len(node.Values)
+			r.modifications++
+		}
+		if err != nil || len(node.Values) > 0 {
 			return err
 		}
 	}
 	r.nodeCount--
+	r.valueCount -= len(node.Values)
+	r.modifications++
 
 	switch {
 	case node.Lower != nil:
@@ -347,99 +184,131 @@ func (r *Root[K, V]) takePlace(node, victim *bst.Node[K, V]) {
 }
 
 func (r *Root[K, V]) deleteDoubleChildrenNode(node *bst.Node[K, V]) {
+	var closestNode, child *bst.Node[K, V]
 	if rand.Float32() > 0.5 {
-		closestNode := r.getMax(node.Lower)
-
-		// cloning closest value
-		node.Key = closestNode.Key
-		node.Values = closestNode.Values
-		if closestNode != node.Lower {
-			closestNode.Parent.Greater = closestNode.Lower
-		} else {
-			node.Lower = nil
-		}
-		closestNode.Greater = nil
-		closestNode.Lower = nil
-		closestNode.Parent = nil
-		r.nodePool.Put(closestNode)
+		closestNode = walk.Max(node.Lower)
+		child = closestNode.Lower
 	} else {
-		closestNode := r.getMin(node.Greater)
-
-		// cloning closest value
-		node.Key = closestNode.Key
-		node.Values = closestNode.Values
-		if closestNode != node.Greater {
-			closestNode.Parent.Lower = closestNode.Greater
-		} else {
-			node.Greater = nil
-		}
-		r.nodePool.Put(closestNode)
+		closestNode = walk.Min(node.Greater)
+		child = closestNode.Greater
 	}
-}
 
-func (r *Root[K, V]) deleteValue(node *bst.Node[K, V], value *V) error {
-	for n, v := range node.Values {
-		found, err := r.comparer.CompareValues(*value, v)
-		if err != nil {
-			return err
-		}
-		if found {
-			node.Values = slices.Delete(node.Values, n, n+1)
-			return nil
-		}
+	// cloning closest value
+	node.Key = closestNode.Key
+	node.Values = closestNode.Values
+
+	// the closest node has at most one child, which takes its place
+	if closestNode == closestNode.Parent.Lower {
+		closestNode.Parent.Lower = child
+	} else {
+		closestNode.Parent.Greater = child
 	}
-	return nil
+	if child != nil {
+		child.Parent = closestNode.Parent
+	}
+	closestNode.Parent, closestNode.Greater, closestNode.Lower = nil, nil, nil
+	r.nodePool.Put(closestNode)
 }
 
 // GetAll implements bst.BST.
 func (r *Root[K, V]) GetAll() iter.Seq[V] {
-	return func(yield func(V) bool) {
-		if !r.initialized {
-			return
-		}
-		_ = r.getAll(&r.Node, yield)
-	}
+	return guard(r, walk.Values(r.root()))
 }
 
-func (r *Root[K, V]) getAll(node *bst.Node[K, V], yield func(V) bool) bool {
-	if node.Lower != nil {
-		if !r.getAll(node.Lower, yield) {
-			return false
-		}
-	}
-	for _, value := range node.Values {
-		if !yield(value) {
-			return false
-		}
-	}
-	if node.Greater == nil {
-		return true
-	}
-	return r.getAll(node.Greater, yield)
+// All implements bst.EntryIterator.
+func (r *Root[K, V]) All() iter.Seq2[K, V] {
+	return guard2(r, walk.Entries(r.root()))
 }
 
-// GetMax implements bst.BST.
-func (r *Root[K, V]) GetMax() *bst.Node[K, V] {
-	return r.getMax(&r.Node)
+// Groups implements bst.EntryIterator.
+func (r *Root[K, V]) Groups() iter.Seq2[K, []V] {
+	return guard2(r, walk.Groups(r.root()))
+}
+
+// GroupsContext implements bst.EntryIterator.
+func (r *Root[K, V]) GroupsContext(ctx context.Context) iter.Seq2[bst.Entry[K, []V], error] {
+	return guardErr(r, walk.GroupsContext(ctx, r.root()))
+}
+
+// QueryEntries implements bst.EntryIterator.
+func (r *Root[K, V]) QueryEntries(query bst.Query[K]) iter.Seq2[bst.Entry[K, V], error] {
+	return guardErr(r, walk.QueryEntries(r.comparer, r.root(), query))
+}
+
+// QueryNodes implements bst.EntryIterator.
+func (r *Root[K, V]) QueryNodes(query bst.Query[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return guardErr(r, walk.QueryNodes(r.comparer, r.root(), query))
+}
+
+// QueryIn implements bst.SetQuerier.
+func (r *Root[K, V]) QueryIn(set bst.QuerySet[K]) iter.Seq2[V, error] {
+	return guardErr(r, walk.QueryIn(r.comparer, r.root(), set))
+}
+
+// QueryRange implements bst.RangeQuerier.
+func (r *Root[K, V]) QueryRange(locate bst.RangeFunc[K]) iter.Seq2[V, error] {
+	return guardErr(r, walk.QueryRange(r.root(), locate))
 }
 
-func (r *Root[K, V]) getMax(node *bst.Node[K, V]) *bst.Node[K, V] {
-	for node.Greater != nil {
-		node = node.Greater
+// QueryContext implements bst.ContextQuerier.
+func (r *Root[K, V]) QueryContext(ctx context.Context, query bst.Query[K]) iter.Seq2[V, error] {
+	return guardErr(r, walk.QueryContext(ctx, r.comparer, r.root(), query))
+}
+
+// GetAllContext implements bst.ContextQuerier.
+func (r *Root[K, V]) GetAllContext(ctx context.Context) iter.Seq2[V, error] {
+	return guardErr(r, walk.ValuesContext(ctx, r.root()))
+}
+
+// GetAllDesc implements bst.Descender.
+func (r *Root[K, V]) GetAllDesc() iter.Seq[V] {
+	return guard(r, walk.ValuesDesc(r.root()))
+}
+
+// QueryDesc implements bst.Descender.
+func (r *Root[K, V]) QueryDesc(query bst.Query[K]) iter.Seq2[V, error] {
+	return guardErr(r, walk.QueryDesc(r.comparer, r.root(), query))
+}
+
+// Cursor implements bst.Navigable.
+func (r *Root[K, V]) Cursor() *bst.Cursor[K, V] {
+	return bst.NewCursor(r.root, r.comparer)
+}
+
+// Floor implements bst.Nearest.
+func (r *Root[K, V]) Floor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Floor(r.comparer, r.root(), key)
+}
+
+// Ceiling implements bst.Nearest.
+func (r *Root[K, V]) Ceiling(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Ceiling(r.comparer, r.root(), key)
+}
+
+// Predecessor implements bst.Nearest.
+func (r *Root[K, V]) Predecessor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Predecessor(r.comparer, r.root(), key)
+}
+
+// Successor implements bst.Nearest.
+func (r *Root[K, V]) Successor(key K) (*bst.Node[K, V], bool, error) {
+	return walk.Successor(r.comparer, r.root(), key)
+}
+
+// GetMax implements bst.BST.
+func (r *Root[K, V]) GetMax() *bst.Node[K, V] {
+	if !r.initialized {
+		return nil
 	}
-	return node
+	return walk.Max(&r.Node)
 }
 
 // GetMin implements bst.BST.
 func (r *Root[K, V]) GetMin() *bst.Node[K, V] {
-	return r.getMin(&r.Node)
-}
-
-func (r *Root[K, V]) getMin(node *bst.Node[K, V]) *bst.Node[K, V] {
-	for node.Lower != nil {
-		node = node.Lower
+	if !r.initialized {
+		return nil
 	}
-	return node
+	return walk.Min(&r.Node)
 }
 
 // GetNumberOfKeys implements bst.BST.
@@ -447,21 +316,25 @@ func (r *Root[K, V]) GetNumberOfKeys() int {
 	return r.nodeCount
 }
 
+// GetNumberOfValues implements bst.Counter.
+func (r *Root[K, V]) GetNumberOfValues() int {
+	return r.valueCount
+}
+
+// Validate checks the structure of the tree with bst.CheckTree.
+func (r *Root[K, V]) Validate() error {
+	return bst.CheckTree(r.root(), r.comparer, r.unique, r.nodeCount, r.valueCount)
+}
+
 // Update implements bst.BST.
 func (r *Root[K, V]) Update(key K, old V, nw V) error {
 	node, err := r.Search(key)
 	if err != nil || node == nil {
 		return err
 	}
-	for n, value := range node.Values {
-		equals, err := r.comparer.CompareValues(value, old)
-		if err != nil {
-			return err
-		}
-		if equals {
-			node.Values[n] = nw
-			break
-		}
+	replaced, err := walk.ReplaceValue(r.comparer, node, old, nw)
+	if replaced {
+		r.modifications++
 	}
-	return nil
+	return err
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/vinicius-lino-figueiredo/bst"
 	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
 	"github.com/vinicius-lino-figueiredo/bst/adapter/unbalanced"
+	"github.com/vinicius-lino-figueiredo/bst/bsttest"
 )
 
 type BSTTestSuite struct {
@@ -281,6 +282,32 @@ func (s *BSTTestSuite) TestDeleteRoot() {
 
 }
 
+func (s *BSTTestSuite) TestDeleteTwoChildren() {
+	// the node replacing the deleted one is picked at random on either side,
+	// and both candidates have a child that must be promoted in their place
+	for range 32 {
+		b := unbalanced.NewBST(false, 0, comparer.NewComparer[string, int]())
+		for i, key := range []string{"m", "f", "t", "h", "g", "p", "r"} {
+			s.NoError(b.Insert(key, i))
+		}
+
+		s.NoError(b.Delete("m", nil))
+		s.Equal(6, b.GetNumberOfKeys())
+		for _, key := range []string{"g", "r"} {
+			node, err := b.Search(key)
+			s.NoError(err)
+			s.Require().NotNil(node.Parent, key)
+			s.True(node.Parent.Lower == node || node.Parent.Greater == node, key)
+		}
+
+		values := make([]int, 0, 6)
+		for v := range b.GetAll() {
+			values = append(values, v)
+		}
+		s.Equal([]int{1, 4, 3, 5, 6, 2}, values)
+	}
+}
+
 func (s *BSTTestSuite) TestSimpleQuery() {
 	data, err := s.fetch(s.b.Query(bst.Query[string]{
 		GreaterThan: &bst.Bound[string]{
@@ -447,6 +474,22 @@ func (s *BSTTestSuite) TestQuery() {
 	s.Equal([]int{42, 23, 63, 55, 88, 33}, data)
 }
 
+func (s *BSTTestSuite) TestQueryLeafBelowLowerBound() {
+	b := unbalanced.NewBST(false, 0, comparer.NewComparer[string, int]())
+	s.NoError(b.Insert("e", 5))
+	s.NoError(b.Insert("h", 8))
+	s.NoError(b.Insert("b", 2))
+
+	// "b" is below the lower bound and has no greater child, while the root
+	// does
+	data, err := s.fetch(b.Query(bst.Query[string]{
+		LowerThan:   &bst.Bound[string]{Value: "d"},
+		GreaterThan: &bst.Bound[string]{Value: "c"}},
+	))
+	s.NoError(err)
+	s.Equal([]int{}, data)
+}
+
 func (s *BSTTestSuite) fetch(i iter.Seq2[int, error]) ([]int, error) {
 	res := make([]int, 0, 32)
 	for v, err := range i {
@@ -468,6 +511,154 @@ func (s *BSTTestSuite) TestUpdate() {
 	s.Equal([]int{10}, s.b.Node.Values)
 }
 
+func (s *BSTTestSuite) TestValidate() {
+	s.NoError(s.b.Validate())
+
+	empty := unbalanced.NewBST(false, 0, comparer.NewComparer[string, int]()).(*unbalanced.Root[string, int])
+	s.NoError(empty.Validate())
+
+	var invalid bst.ErrInvalidTree
+
+	// parent pointer not updated
+	Felix, err := s.b.Search("Felix")
+	s.NoError(err)
+	Alice := Felix.Parent
+	s.Equal("Alice", Alice.Key)
+	Felix.Parent = &s.b.Node
+	s.ErrorAs(s.b.Validate(), &invalid)
+	s.Equal(bst.InvariantParent, invalid.Invariant)
+	s.Equal("Felix", invalid.Key)
+	Felix.Parent = Alice
+	s.NoError(s.b.Validate())
+
+	// ordering violated
+	Felix.Key = "Zeus"
+	s.ErrorAs(s.b.Validate(), &invalid)
+	s.Equal(bst.InvariantOrder, invalid.Invariant)
+	s.Equal("Hugo", invalid.Key)
+	Felix.Key = "Felix"
+
+	// empty node left behind
+	values := Felix.Values
+	Felix.Values = Felix.Values[:0]
+	s.ErrorAs(s.b.Validate(), &invalid)
+	s.Equal(bst.InvariantValues, invalid.Invariant)
+	s.Equal("Felix", invalid.Key)
+	Felix.Values = values
+
+	// detached subtree
+	Alice.Greater = nil
+	s.ErrorAs(s.b.Validate(), &invalid)
+	s.Equal(bst.InvariantCount, invalid.Invariant)
+	s.Nil(invalid.Key)
+	Alice.Greater = Felix
+	s.NoError(s.b.Validate())
+
+	// more than one value in a unique tree
+	unique := unbalanced.NewBST(true, 0, comparer.NewComparer[string, int]()).(*unbalanced.Root[string, int])
+	s.NoError(unique.Insert("a", 1))
+	unique.Values = append(unique.Values, 2)
+	s.ErrorAs(unique.Validate(), &invalid)
+	s.Equal(bst.InvariantUnique, invalid.Invariant)
+	s.EqualError(invalid, "invalid tree: unique nodes hold one value: node a: 2 values")
+}
+
 func TestBSTTestSuite(t *testing.T) {
 	suite.Run(t, new(BSTTestSuite))
 }
+
+func TestConformance(t *testing.T) {
+	bsttest.Run(t, func(unique bool, comparer bst.Comparer[string, int]) bst.BST[string, int] {
+		return unbalanced.NewBST(unique, 0, comparer)
+	})
+}
+
+func (s *BSTTestSuite) TestQueryIn() {
+	counter := &countingComparer{Comparer: comparer.NewComparer[int, int]()}
+	b, err := unbalanced.NewFromSorted(true, 0, counter, func(yield func(int, int) bool) {
+		for i := range 1023 {
+			if !yield(i, i) {
+				return
+			}
+		}
+	})
+	s.NoError(err)
+	counter.compared = 0
+
+	values := make([]int, 0, 8)
+	for v, err := range b.(bst.SetQuerier[int, int]).QueryIn(bst.QuerySet[int]{
+		Keys: []int{700, 3, 3},
+		Ranges: []bst.Query[int]{
+			{GreaterThan: &bst.Bound[int]{Value: 510}, LowerThan: &bst.Bound[int]{Value: 513, IncludeEqual: true}},
+		},
+	}) {
+		s.NoError(err)
+		values = append(values, v)
+	}
+	s.Equal([]int{3, 511, 512, 513, 700}, values)
+	// a full scan would visit all 1023 nodes
+	s.Less(counter.compared, 300)
+
+	counter.compared = 0
+	values = values[:0]
+	for v, err := range b.(bst.SetQuerier[int, int]).QueryIn(bst.QuerySet[int]{
+		Ranges: []bst.Query[int]{
+			{LowerThan: &bst.Bound[int]{Value: 3}},
+			{GreaterThan: &bst.Bound[int]{Value: 1020}},
+		},
+		ExceptKeys: []int{1, 1021},
+	}) {
+		s.NoError(err)
+		values = append(values, v)
+	}
+	s.Equal([]int{0, 2, 1022}, values)
+	s.Less(counter.compared, 200)
+}
+
+type countingComparer struct {
+	bst.Comparer[int, int]
+	compared int
+}
+
+func (c *countingComparer) CompareKeys(a, b int) (int, error) {
+	c.compared++
+	return c.Comparer.CompareKeys(a, b)
+}
+
+func (s *BSTTestSuite) TestQueryPrefix() {
+	b := unbalanced.NewBST(true, 0, comparer.NewComparer[string, int]())
+	keys := []string{"tenant/1", "tenant/12", "tenant/12/a", "tenant/12/b", "tenant/120", "tenant/13", "tenant/é", "tenant/ê", "\xff", "\xff\xff", "\xff\xffa"}
+	for i, key := range keys {
+		s.NoError(b.Insert(key, i))
+	}
+
+	for _, c := range []struct {
+		prefix   string
+		expected []int
+	}{
+		{"tenant/12/", []int{2, 3}},
+		{"tenant/12", []int{1, 2, 3, 4}},
+		{"tenant/é", []int{6}},
+		{"\xff\xff", []int{9, 10}},
+		{"", []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10}},
+		{"other", []int{}},
+	} {
+		values := make([]int, 0, len(c.expected))
+		for v, err := range b.Query(comparer.Prefix(c.prefix)) {
+			s.NoError(err)
+			values = append(values, v)
+		}
+		s.Equal(c.expected, values, "%q", c.prefix)
+	}
+
+	bytesTree := unbalanced.NewBST(true, 0, comparer.NewBytesComparer[int]())
+	for i, key := range keys {
+		s.NoError(bytesTree.Insert([]byte(key), i))
+	}
+	values := make([]int, 0, 2)
+	for v, err := range bytesTree.Query(comparer.Prefix([]byte("\xff\xff"))) {
+		s.NoError(err)
+		values = append(values, v)
+	}
+	s.Equal([]int{9, 10}, values)
+}
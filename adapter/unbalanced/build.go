@@ -0,0 +1,110 @@
+package unbalanced
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/internal/walk"
+)
+
+// NewFromSorted creates a tree of minimal height holding entries, in O(n).
+// The first three arguments have the same meaning as in NewBST. Entries must
+// be sorted in ascending key order according to comparer; consecutive entries
+// with equal keys share a node, keeping their values in order. It returns a
+// bst.ErrInvalidTree if a key is lower than the one before it and a
+// bst.ErrUniqueViolated if a key repeats in a unique tree.
+func NewFromSorted[K any, V any](unique bool, creationSize int, comparer bst.Comparer[K, V], entries iter.Seq2[K, V]) (bst.BST[K, V], error) {
+	root := NewBST(unique, creationSize, comparer).(*Root[K, V])
+	groups := make([]bst.Entry[K, []V], 0, 64)
+	for key, value := range entries {
+		if len(groups) > 0 {
+			last := &groups[len(groups)-1]
+			comparison, err := comparer.CompareKeys(last.Key, key)
+			if err != nil {
+				return nil, err
+			}
+			switch {
+			case comparison > 0:
+				return nil, bst.ErrInvalidTree{
+					Key:       key,
+					Invariant: bst.InvariantOrder,
+					Detail:    fmt.Sprintf("lower than %v", last.Key),
+				}
+			case comparison == 0:
+				if unique {
+					return nil, bst.ErrUniqueViolated{Key: key}
+				}
+				last.Value = append(last.Value, value)
+				continue
+			}
+		}
+		values := make([]V, 0, root.creationSize)
+		groups = append(groups, bst.Entry[K, []V]{Key: key, Value: append(values, value)})
+	}
+	root.build(groups, nil)
+	return root, nil
+}
+
+// Rebalance rebuilds the tree into one of minimal height, in O(n), reusing
+// its own nodes. Every key keeps its Values slice, but may move to another
+// node: nodes obtained before calling Rebalance must be searched again.
+func (r *Root[K, V]) Rebalance() {
+	if !r.initialized {
+		return
+	}
+	groups := make([]bst.Entry[K, []V], 0, r.nodeCount)
+	spare := make([]*bst.Node[K, V], 0, r.nodeCount-1)
+	for node := range walk.Nodes(&r.Node) {
+		groups = append(groups, bst.Entry[K, []V]{Key: node.Key, Value: node.Values})
+		if node != &r.Node {
+			spare = append(spare, node)
+		}
+	}
+	r.build(groups, spare)
+	r.modifications++
+}
+
+// Height returns the number of nodes on the longest path from the root down
+// to a leaf. It visits the whole tree, so it costs O(n).
+func (r *Root[K, V]) Height() int {
+	return walk.Height(r.root())
+}
+
+// build replaces the contents of the tree with groups, which must be
+// sorted by key, linking them into a tree of minimal height. The middle group
+// goes to the root and each half is built the same way under it. Nodes are
+// taken from spare first, and from the pool once it runs out.
+func (r *Root[K, V]) build(groups []bst.Entry[K, []V], spare []*bst.Node[K, V]) {
+	if len(groups) == 0 {
+		return
+	}
+	mid := len(groups) / 2
+	r.Key, r.Values = groups[mid].Key, groups[mid].Value
+	r.Parent = nil
+	r.Lower = r.buildSubtree(groups[:mid], &r.Node, &spare)
+	r.Greater = r.buildSubtree(groups[mid+1:], &r.Node, &spare)
+	r.initialized = true
+	r.nodeCount = len(groups)
+	r.valueCount = 0
+	for _, group := range groups {
+		r.valueCount += len(group.Value)
+	}
+}
+
+func (r *Root[K, V]) buildSubtree(groups []bst.Entry[K, []V], parent *bst.Node[K, V], spare *[]*bst.Node[K, V]) *bst.Node[K, V] {
+	if len(groups) == 0 {
+		return nil
+	}
+	mid := len(groups) / 2
+	var node *bst.Node[K, V]
+	if n := len(*spare); n > 0 {
+		node, *spare = (*spare)[n-1], (*spare)[:n-1]
+	} else {
+		node = r.nodePool.Get().(*bst.Node[K, V])
+	}
+	node.Key, node.Values, node.Parent = groups[mid].Key, groups[mid].Value, parent
+	node.Lower = r.buildSubtree(groups[:mid], node, spare)
+	node.Greater = r.buildSubtree(groups[mid+1:], node, spare)
+	return node
+}
@@ -0,0 +1,124 @@
+package unbalanced_test
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/unbalanced"
+)
+
+func (s *BSTTestSuite) TestNewFromSorted() {
+	entries := func(yield func(string, int) bool) {
+		for i := range 1000 {
+			if !yield(fmt.Sprintf("%03d", i/2), i) {
+				return
+			}
+		}
+	}
+
+	b, err := unbalanced.NewFromSorted(false, 0, comparer.NewComparer[string, int](), entries)
+	s.NoError(err)
+	s.NoError(b.(*unbalanced.Root[string, int]).Validate())
+	s.Equal(500, b.GetNumberOfKeys())
+	s.Equal(9, height(b.GetMin()))
+
+	node, err := b.Search("123")
+	s.NoError(err)
+	s.Equal([]int{246, 247}, node.Values)
+
+	values := slices.Collect(b.GetAll())
+	s.Len(values, 1000)
+	s.True(slices.IsSorted(values))
+
+	// the tree keeps working as usual
+	s.NoError(b.Insert("123", 0))
+	s.NoError(b.Delete("000", nil))
+	s.NoError(b.(*unbalanced.Root[string, int]).Validate())
+}
+
+func (s *BSTTestSuite) TestNewFromSortedUnique() {
+	source := map[int]string{1: "a", 2: "b", 3: "c"}
+	b, err := unbalanced.NewFromSorted(true, 0, comparer.NewComparer[int, string](), func(yield func(int, string) bool) {
+		for _, k := range slices.Sorted(maps.Keys(source)) {
+			if !yield(k, source[k]) {
+				return
+			}
+		}
+	})
+	s.NoError(err)
+	s.NoError(b.(*unbalanced.Root[int, string]).Validate())
+	s.Equal(2, b.(*unbalanced.Root[int, string]).Key)
+	s.ErrorAs(b.Insert(2, "again"), &bst.ErrUniqueViolated{})
+
+	_, err = unbalanced.NewFromSorted(true, 0, comparer.NewComparer[int, string](), func(yield func(int, string) bool) {
+		_ = yield(1, "a") && yield(2, "b") && yield(2, "c")
+	})
+	s.Equal(bst.ErrUniqueViolated{Key: 2}, err)
+}
+
+func (s *BSTTestSuite) TestNewFromSortedErrors() {
+	var invalid bst.ErrInvalidTree
+	_, err := unbalanced.NewFromSorted(false, 0, comparer.NewComparer[int, int](), func(yield func(int, int) bool) {
+		_ = yield(1, 1) && yield(3, 3) && yield(2, 2)
+	})
+	s.ErrorAs(err, &invalid)
+	s.Equal(bst.InvariantOrder, invalid.Invariant)
+	s.Equal(2, invalid.Key)
+
+	b, err := unbalanced.NewFromSorted(false, 0, comparer.NewComparer[int, int](), func(func(int, int) bool) {})
+	s.NoError(err)
+	s.Equal(0, b.GetNumberOfKeys())
+	s.NoError(b.(*unbalanced.Root[int, int]).Validate())
+}
+
+func (s *BSTTestSuite) TestRebalance() {
+	b := unbalanced.NewBST(false, 0, comparer.NewComparer[int, int]()).(*unbalanced.Root[int, int])
+	b.Rebalance()
+	s.Zero(b.Height())
+
+	for i := range 1023 {
+		s.NoError(b.Insert(i, i))
+	}
+	s.NoError(b.Insert(500, -500))
+	s.Equal(1023, b.Height())
+
+	node, err := b.Search(500)
+	s.NoError(err)
+	values := node.Values
+	nodes := make(map[*bst.Node[int, int]]bool, 1023)
+	for node := range b.QueryNodes(bst.Gte(0)) {
+		nodes[node] = true
+	}
+
+	b.Rebalance()
+	s.NoError(b.Validate())
+	s.Equal(10, b.Height())
+	s.Equal(1023, b.GetNumberOfKeys())
+	s.Equal(1024, b.GetNumberOfValues())
+
+	// values keep their slice, even if the key moved to another node
+	node, err = b.Search(500)
+	s.NoError(err)
+	s.Equal([]int{500, -500}, node.Values)
+	s.Same(&values[0], &node.Values[0])
+
+	// and no node is allocated
+	for node := range b.QueryNodes(bst.Gte(0)) {
+		s.True(nodes[node], node.Key)
+	}
+
+	// the tree keeps working as usual
+	s.NoError(b.Delete(511, nil))
+	s.NoError(b.Insert(2000, 0))
+	s.NoError(b.Validate())
+}
+
+func (s *BSTTestSuite) TestHeight() {
+	s.Equal(subtreeHeight(&s.b.Node), s.b.Height())
+	s.b.Rebalance()
+	s.NoError(s.b.Validate())
+	s.Equal(4, s.b.Height())
+}
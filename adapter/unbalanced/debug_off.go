@@ -0,0 +1,5 @@
+//go:build !bstdebug
+
+package unbalanced
+
+const debug = false
@@ -0,0 +1,7 @@
+//go:build bstdebug
+
+package unbalanced
+
+// debug makes iterators that cannot report errors panic when their tree is
+// modified mid-walk.
+const debug = true
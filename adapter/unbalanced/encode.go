@@ -0,0 +1,228 @@
+package unbalanced
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/internal/walk"
+)
+
+// The encoded form starts with magic and version, followed by a flags byte,
+// the creation size and the number of nodes as uvarints. Every node follows
+// in ascending key order as its length-prefixed key, the number of values and
+// each length-prefixed value.
+const (
+	magic   = "BST"
+	version = 1
+
+	flagUnique = 1 << 0
+
+	// maxLength bounds the length prefixes accepted by Decode.
+	maxLength = 1 << 30
+	// maxCapacity bounds the capacities Decode preallocates from counts read
+	// from its input, which are then only exceeded as data actually arrives,
+	// so that corrupt data cannot trigger huge allocations. It also bounds the
+	// creation size.
+	maxCapacity = 1 << 16
+)
+
+// ErrFormat is returned by Decode when its input was not produced by Encode,
+// was truncated or uses an unsupported version.
+type ErrFormat struct {
+	Detail string
+}
+
+func (e ErrFormat) Error() string {
+	return "invalid encoded tree: " + e.Detail
+}
+
+// Encode writes the whole tree to w, using keys and values to encode each key
+// and value. The header records the format version, the unique mode and the
+// creation size, so that Decode can restore the tree as it was configured.
+func (r *Root[K, V]) Encode(w io.Writer, keys bst.Codec[K], values bst.Codec[V]) error {
+	bw := bufio.NewWriter(w)
+
+	buf := make([]byte, 0, 64)
+	buf = append(buf, magic...)
+	buf = append(buf, version)
+	var flags byte
+	if r.unique {
+		flags |= flagUnique
+	}
+	buf = append(buf, flags)
+	buf = binary.AppendUvarint(buf, uint64(r.creationSize))
+	buf = binary.AppendUvarint(buf, uint64(r.nodeCount))
+	if _, err := bw.Write(buf); err != nil {
+		return err
+	}
+
+	for node := range walk.Nodes(r.root()) {
+		data, err := keys.Encode(node.Key)
+		if err != nil {
+			return err
+		}
+		buf = binary.AppendUvarint(buf[:0], uint64(len(data)))
+		buf = append(buf, data...)
+		buf = binary.AppendUvarint(buf, uint64(len(node.Values)))
+		for _, value := range node.Values {
+			if data, err = values.Encode(value); err != nil {
+				return err
+			}
+			buf = binary.AppendUvarint(buf, uint64(len(data)))
+			buf = append(buf, data...)
+		}
+		if _, err = bw.Write(buf); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+// Decode reads a tree written by Encode, decoding its contents with keys and
+// values. The tree is rebuilt with minimal height, whatever the shape of the
+// encoded one. Keys must be strictly ascending according to comparer, and the
+// creation size must not exceed 65536.
+func Decode[K any, V any](r io.Reader, comparer bst.Comparer[K, V], keys bst.Codec[K], values bst.Codec[V]) (bst.BST[K, V], error) {
+	d := decoder{r: bufio.NewReader(r)}
+
+	header := make([]byte, len(magic)+2)
+	if err := d.read(header); err != nil {
+		return nil, err
+	}
+	if string(header[:len(magic)]) != magic {
+		return nil, ErrFormat{Detail: "missing header"}
+	}
+	if header[len(magic)] != version {
+		return nil, ErrFormat{Detail: fmt.Sprintf("unsupported version %d", header[len(magic)])}
+	}
+	unique := header[len(magic)+1]&flagUnique != 0
+	creationSize, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	if creationSize > maxCapacity {
+		return nil, ErrFormat{Detail: fmt.Sprintf("creation size %d out of bounds", creationSize)}
+	}
+	nodeCount, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+
+	root := NewBST(unique, int(creationSize), comparer).(*Root[K, V])
+	groups := make([]bst.Entry[K, []V], 0, min(nodeCount, maxCapacity))
+	for range nodeCount {
+		data, err := d.bytes()
+		if err != nil {
+			return nil, err
+		}
+		key, err := keys.Decode(data)
+		if err != nil {
+			return nil, err
+		}
+		if err = root.checkNext(groups, key); err != nil {
+			return nil, err
+		}
+
+		count, err := d.length()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case count == 0:
+			return nil, bst.ErrInvalidTree{Key: key, Invariant: bst.InvariantValues, Detail: "no values"}
+		case unique && count > 1:
+			return nil, bst.ErrUniqueViolated{Key: key}
+		}
+		group := make([]V, 0, min(count, maxCapacity))
+		for range count {
+			if data, err = d.bytes(); err != nil {
+				return nil, err
+			}
+			value, err := values.Decode(data)
+			if err != nil {
+				return nil, err
+			}
+			group = append(group, value)
+		}
+		groups = append(groups, bst.Entry[K, []V]{Key: key, Value: group})
+	}
+
+	root.build(groups, nil)
+	return root, nil
+}
+
+// checkNext fails unless key is greater than the last key of groups.
+func (r *Root[K, V]) checkNext(groups []bst.Entry[K, []V], key K) error {
+	if len(groups) == 0 {
+		return nil
+	}
+	comparison, err := r.comparer.CompareKeys(groups[len(groups)-1].Key, key)
+	if err != nil {
+		return err
+	}
+	if comparison >= 0 {
+		return bst.ErrInvalidTree{
+			Key:       key,
+			Invariant: bst.InvariantOrder,
+			Detail:    fmt.Sprintf("not greater than %v", groups[len(groups)-1].Key),
+		}
+	}
+	return nil
+}
+
+type decoder struct {
+	r *bufio.Reader
+}
+
+func (d decoder) read(buf []byte) error {
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return d.wrap(err)
+	}
+	return nil
+}
+
+func (d decoder) length() (uint64, error) {
+	n, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return 0, d.wrap(err)
+	}
+	if n > maxLength {
+		return 0, ErrFormat{Detail: fmt.Sprintf("length %d out of bounds", n)}
+	}
+	return n, nil
+}
+
+// bytes reads a length-prefixed field. Its buffer starts with at most
+// maxCapacity bytes and doubles as data arrives, rather than trusting the
+// prefix.
+func (d decoder) bytes() ([]byte, error) {
+	n, err := d.length()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, min(n, maxCapacity))
+	for uint64(len(buf)) < n {
+		if len(buf) == cap(buf) {
+			buf = slices.Grow(buf, int(min(n-uint64(len(buf)), uint64(len(buf)))))
+		}
+		end := int(min(n, uint64(cap(buf))))
+		if err = d.read(buf[len(buf):end]); err != nil {
+			return nil, err
+		}
+		buf = buf[:end]
+	}
+	return buf, nil
+}
+
+// wrap reports a premature end of input as a format error.
+func (d decoder) wrap(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return ErrFormat{Detail: "unexpected end of data"}
+	}
+	return err
+}
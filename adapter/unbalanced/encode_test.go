@@ -0,0 +1,191 @@
+package unbalanced_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"iter"
+	"runtime"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/codec"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/unbalanced"
+)
+
+func (s *BSTTestSuite) TestEncode() {
+	var buf bytes.Buffer
+	s.NoError(s.b.Encode(&buf, codec.NewString(), codec.NewGob[int]()))
+	encoded := bytes.Clone(buf.Bytes())
+
+	decoded, err := unbalanced.Decode(&buf, comparer.NewComparer[string, int](), codec.NewString(), codec.NewGob[int]())
+	s.NoError(err)
+	b := decoded.(*unbalanced.Root[string, int])
+	s.NoError(b.Validate())
+	s.Equal(s.b.GetNumberOfKeys(), b.GetNumberOfKeys())
+	s.Equal(s.b.GetNumberOfValues(), b.GetNumberOfValues())
+	s.Equal(s.collect(s.b.Groups()), s.collect(b.Groups()))
+
+	// still non-unique
+	s.NoError(b.Insert("Alice", 1))
+
+	// encoding again gives the same bytes, whatever the shape of the tree
+	value := 1
+	s.NoError(b.Delete("Alice", &value))
+	buf.Reset()
+	s.NoError(b.Encode(&buf, codec.NewString(), codec.NewGob[int]()))
+	s.Equal(encoded, buf.Bytes())
+}
+
+func (s *BSTTestSuite) TestEncodeBalanced() {
+	b := unbalanced.NewBST(true, 0, comparer.NewComparer[int64, string]()).(*unbalanced.Root[int64, string])
+	for i := range int64(1023) {
+		s.NoError(b.Insert(i, fmt.Sprint(i)))
+	}
+
+	var buf bytes.Buffer
+	s.NoError(b.Encode(&buf, codec.NewFixed[int64](), codec.NewString()))
+	decoded, err := unbalanced.Decode(&buf, comparer.NewComparer[int64, string](), codec.NewFixed[int64](), codec.NewString())
+	s.NoError(err)
+	s.NoError(decoded.(*unbalanced.Root[int64, string]).Validate())
+	s.Equal(10, height(decoded.GetMin()))
+	s.Equal(1023, decoded.GetNumberOfKeys())
+
+	node, err := decoded.Search(512)
+	s.NoError(err)
+	s.Equal([]string{"512"}, node.Values)
+
+	// still unique
+	s.ErrorAs(decoded.Insert(512, "again"), &bst.ErrUniqueViolated{})
+}
+
+func (s *BSTTestSuite) TestEncodeEmpty() {
+	b := unbalanced.NewBST(false, 3, comparer.NewComparer[string, string]()).(*unbalanced.Root[string, string])
+
+	var buf bytes.Buffer
+	s.NoError(b.Encode(&buf, codec.NewString(), codec.NewString()))
+	s.Equal([]byte{'B', 'S', 'T', 1, 0, 3, 0}, buf.Bytes())
+
+	decoded, err := unbalanced.Decode(&buf, comparer.NewComparer[string, string](), codec.NewString(), codec.NewString())
+	s.NoError(err)
+	s.Equal(0, decoded.GetNumberOfKeys())
+	s.NoError(decoded.Insert("a", "b"))
+	s.NoError(decoded.(*unbalanced.Root[string, string]).Validate())
+}
+
+func (s *BSTTestSuite) TestDecodeErrors() {
+	c := comparer.NewComparer[string, string]()
+	decode := func(data []byte) error {
+		_, err := unbalanced.Decode(bytes.NewReader(data), c, codec.NewString(), codec.NewString())
+		return err
+	}
+
+	var format unbalanced.ErrFormat
+	s.ErrorAs(decode(nil), &format)
+	s.ErrorAs(decode([]byte("JSON")), &format)
+	s.ErrorAs(decode([]byte{'B', 'S', 'T', 2, 0, 1, 0}), &format)
+	s.Equal("invalid encoded tree: unsupported version 2", format.Error())
+	s.ErrorAs(decode([]byte{'B', 'S', 'T', 1, 0, 1, 1, 1, 'a', 1, 5, 'b'}), &format)
+	s.Equal("invalid encoded tree: unexpected end of data", format.Error())
+	s.ErrorAs(decode([]byte{'B', 'S', 'T', 1, 0, 1, 1, 0xff, 0xff, 0xff, 0xff, 0x0f}), &format)
+
+	var invalid bst.ErrInvalidTree
+	s.ErrorAs(decode([]byte{'B', 'S', 'T', 1, 0, 1, 1, 1, 'a', 0}), &invalid)
+	s.Equal(bst.InvariantValues, invalid.Invariant)
+	s.ErrorAs(decode([]byte{'B', 'S', 'T', 1, 0, 1, 2, 1, 'b', 1, 0, 1, 'a', 1, 0}), &invalid)
+	s.Equal(bst.InvariantOrder, invalid.Invariant)
+	s.Equal("a", invalid.Key)
+	s.ErrorAs(decode([]byte{'B', 'S', 'T', 1, flagUnique, 1, 1, 1, 'a', 2, 0, 0}), &bst.ErrUniqueViolated{})
+
+	// truncated inputs
+	var buf bytes.Buffer
+	s.NoError(s.b.Encode(&buf, codec.NewString(), codec.NewGob[int]()))
+	encoded := buf.Bytes()
+	for i := range len(encoded) {
+		_, err := unbalanced.Decode(bytes.NewReader(encoded[:i]), comparer.NewComparer[string, int](), codec.NewString(), codec.NewGob[int]())
+		s.ErrorAs(err, &format, "%d bytes", i)
+	}
+
+	// counts and lengths beyond the data are not trusted
+	huge := binary.AppendUvarint(nil, 1<<30)
+	for _, data := range [][]byte{
+		append([]byte{'B', 'S', 'T', 1, 0}, huge...),
+		append([]byte{'B', 'S', 'T', 1, 0, 1}, huge...),
+		append([]byte{'B', 'S', 'T', 1, 0, 1, 1}, huge...),
+		append([]byte{'B', 'S', 'T', 1, 0, 1, 1, 1, 'a'}, huge...),
+		append(append([]byte{'B', 'S', 'T', 1, 0, 1, 1, 1, 'a', 1}, huge...), 0),
+	} {
+		_, err := unbalanced.Decode(bytes.NewReader(data), comparer.NewComparer[string, int64](), codec.NewString(), codec.NewFixed[int64]())
+		s.ErrorAs(err, &format, "%v", data)
+	}
+
+	// the creation size only applies to nodes created after decoding
+	data := append([]byte{'B', 'S', 'T', 1, 0}, binary.AppendUvarint(nil, 1<<16)...)
+	data = binary.AppendUvarint(data, 300)
+	fixed := codec.NewFixed[int64]()
+	for i := range 300 {
+		key := fmt.Sprintf("%03d", i)
+		value, err := fixed.Encode(int64(i))
+		s.Require().NoError(err)
+		data = binary.AppendUvarint(data, uint64(len(key)))
+		data = append(data, key...)
+		data = append(data, 1)
+		data = binary.AppendUvarint(data, uint64(len(value)))
+		data = append(data, value...)
+	}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	decoded, err := unbalanced.Decode(bytes.NewReader(data), comparer.NewComparer[string, int64](), codec.NewString(), fixed)
+	runtime.ReadMemStats(&after)
+	s.Require().NoError(err)
+	s.Equal(300, decoded.GetNumberOfKeys())
+	s.Less(after.TotalAlloc-before.TotalAlloc, uint64(4<<20))
+
+	// codec errors
+	buf.Reset()
+	s.ErrorIs(s.b.Encode(&buf, failingCodec[string]{}, codec.NewGob[int]()), errCodec)
+	s.ErrorIs(s.b.Encode(&buf, codec.NewString(), failingCodec[int]{}), errCodec)
+	buf.Reset()
+	s.NoError(s.b.Encode(&buf, codec.NewString(), codec.NewGob[int]()))
+	_, err = unbalanced.Decode(bytes.NewReader(buf.Bytes()), comparer.NewComparer[string, int](), failingCodec[string]{}, codec.NewGob[int]())
+	s.ErrorIs(err, errCodec)
+	_, err = unbalanced.Decode(bytes.NewReader(buf.Bytes()), comparer.NewComparer[string, int](), codec.NewString(), failingCodec[int]{})
+	s.ErrorIs(err, errCodec)
+}
+
+const flagUnique = 1
+
+var errCodec = errors.New("codec failed")
+
+type failingCodec[T any] struct{}
+
+func (failingCodec[T]) Encode(T) ([]byte, error) {
+	return nil, errCodec
+}
+
+func (failingCodec[T]) Decode([]byte) (T, error) {
+	return *new(T), errCodec
+}
+
+func height[K any, V any](node *bst.Node[K, V]) int {
+	for node != nil && node.Parent != nil {
+		node = node.Parent
+	}
+	return subtreeHeight(node)
+}
+
+func subtreeHeight[K any, V any](node *bst.Node[K, V]) int {
+	if node == nil {
+		return 0
+	}
+	return 1 + max(subtreeHeight(node.Lower), subtreeHeight(node.Greater))
+}
+
+func (s *BSTTestSuite) collect(i iter.Seq2[string, []int]) []bst.Entry[string, []int] {
+	res := make([]bst.Entry[string, []int], 0, 32)
+	for k, v := range i {
+		res = append(res, bst.Entry[string, []int]{Key: k, Value: v})
+	}
+	return res
+}
@@ -0,0 +1,72 @@
+package unbalanced
+
+import (
+	"iter"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// Writes return nodes to the pool and move keys between nodes, so iterators
+// resuming after one could read reused nodes and yield wrong data. Instead,
+// they compare the number of modifications of the tree to the one it had when
+// they started, and stop as soon as it changes: iterators that can report
+// errors yield a final bst.ErrConcurrentModification, while the others just
+// end early, or panic in builds tagged bstdebug.
+
+// guard stops seq once r is modified.
+func guard[K any, V any, T any](r *Root[K, V], seq iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		start := r.modifications
+		for item := range seq {
+			if !yield(item) {
+				return
+			}
+			if r.modifications != start {
+				modified()
+				return
+			}
+		}
+	}
+}
+
+// guard2 is guard for iter.Seq2.
+func guard2[K any, V any, T any, U any](r *Root[K, V], seq iter.Seq2[T, U]) iter.Seq2[T, U] {
+	return func(yield func(T, U) bool) {
+		start := r.modifications
+		for a, b := range seq {
+			if !yield(a, b) {
+				return
+			}
+			if r.modifications != start {
+				modified()
+				return
+			}
+		}
+	}
+}
+
+// guardErr stops seq once r is modified, yielding
+// bst.ErrConcurrentModification.
+func guardErr[K any, V any, T any](r *Root[K, V], seq iter.Seq2[T, error]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		start := r.modifications
+		for item, err := range seq {
+			if !yield(item, err) {
+				return
+			}
+			if r.modifications != start {
+				var zero T
+				yield(zero, bst.ErrConcurrentModification{})
+				return
+			}
+		}
+	}
+}
+
+// modified is called by iterators that cannot report the modification of
+// their tree.
+func modified() {
+	if debug {
+		panic(bst.ErrConcurrentModification{})
+	}
+}
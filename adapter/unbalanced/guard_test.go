@@ -0,0 +1,97 @@
+package unbalanced_test
+
+import (
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+func (s *BSTTestSuite) TestQueryModified() {
+	values := make([]int, 0, 20)
+	var err error
+	for value, queryErr := range s.b.Query(bst.Query[string]{GreaterThan: &bst.Bound[string]{Value: "A"}}) {
+		if queryErr != nil {
+			err = queryErr
+			break
+		}
+		values = append(values, value)
+		if value == 63 {
+			s.NoError(s.b.Delete("Leo", nil))
+		}
+	}
+	s.Equal([]int{42, 23, 63}, values)
+	s.Equal(bst.ErrConcurrentModification{}, err)
+
+	// descending queries and entries are guarded as well
+	values = values[:0]
+	err = nil
+	for value, queryErr := range s.b.QueryDesc(bst.Query[string]{LowerThan: &bst.Bound[string]{Value: "Z"}}) {
+		if queryErr != nil {
+			err = queryErr
+			break
+		}
+		values = append(values, value)
+		s.NoError(s.b.Insert("Leo", 76))
+	}
+	s.Equal([]int{92}, values)
+	s.Equal(bst.ErrConcurrentModification{}, err)
+
+	count := 0
+	for entry, err := range s.b.QueryEntries(bst.Query[string]{LowerThan: &bst.Bound[string]{Value: "Z"}}) {
+		count++
+		if err != nil {
+			s.Equal(bst.ErrConcurrentModification{}, err)
+			break
+		}
+		s.NoError(s.b.Update(entry.Key, entry.Value, -entry.Value))
+	}
+	s.Equal(2, count)
+}
+
+func (s *BSTTestSuite) TestGetAllModified() {
+	values := make([]int, 0, 20)
+	s.modifying(func() {
+		for value := range s.b.GetAll() {
+			values = append(values, value)
+			if len(values) == 3 {
+				s.NoError(s.b.Insert("Bob", 1))
+			}
+		}
+	})
+	s.Equal([]int{42, 23, 63}, values)
+
+	keys := make([]string, 0, 15)
+	s.modifying(func() {
+		for key := range s.b.Groups() {
+			keys = append(keys, key)
+			value := 42
+			s.NoError(s.b.Delete("Alice", &value))
+		}
+	})
+	s.Equal([]string{"Alice"}, keys)
+}
+
+// modifying runs loop, which modifies the tree it iterates over, accepting the
+// panic of builds tagged bstdebug.
+func (s *BSTTestSuite) modifying(loop func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			s.Equal(bst.ErrConcurrentModification{}, r)
+		}
+	}()
+	loop()
+}
+
+func (s *BSTTestSuite) TestIteratorsUnmodified() {
+	value := 1000
+	values := make([]int, 0, 20)
+	for v, err := range s.b.Query(bst.Query[string]{GreaterThan: &bst.Bound[string]{Value: "A"}}) {
+		s.NoError(err)
+		values = append(values, v)
+
+		// writes that change nothing do not stop iterators
+		s.NoError(s.b.Delete("Bob", nil))
+		s.NoError(s.b.Delete("Alice", &value))
+		s.NoError(s.b.Update("Bob", 1, 2))
+		s.NoError(s.b.Update("Leo", 1, 2))
+	}
+	s.Len(values, 20)
+}
@@ -0,0 +1,153 @@
+package unbalanced
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// jsonEntry is the JSON form of a key and its values.
+type jsonEntry[K any, V any] struct {
+	Key    K   `json:"key"`
+	Values []V `json:"values"`
+}
+
+// ErrEntry wraps an error caused by the entry at Position, counting from
+// zero, of a document read by DecodeJSON.
+type ErrEntry struct {
+	Position int
+	Err      error
+}
+
+func (e ErrEntry) Error() string {
+	return fmt.Sprintf("entry %d: %v", e.Position, e.Err)
+}
+
+func (e ErrEntry) Unwrap() error {
+	return e.Err
+}
+
+// ErrNoEntries is returned by EncodeJSON for trees that do not implement
+// bst.EntryIterator.
+type ErrNoEntries struct{}
+
+func (e ErrNoEntries) Error() string {
+	return "tree does not implement bst.EntryIterator"
+}
+
+// EncodeJSON writes tree to w as a JSON array holding one
+// {"key": ..., "values": [...]} object per key, in ascending key order. Keys
+// and values are encoded with encoding/json. The tree must implement
+// bst.EntryIterator, or ErrNoEntries is returned and nothing is written. Errors
+// yielded while reading the tree, such as bst.ErrConcurrentModification, are
+// returned as they are, leaving an incomplete document in w.
+func EncodeJSON[K any, V any](w io.Writer, tree bst.BST[K, V]) error {
+	entries, ok := tree.(bst.EntryIterator[K, V])
+	if !ok {
+		return ErrNoEntries{}
+	}
+	bw := bufio.NewWriter(w)
+	if err := bw.WriteByte('['); err != nil {
+		return err
+	}
+	first := true
+	for group, err := range entries.GroupsContext(context.Background()) {
+		if err != nil {
+			return err
+		}
+		if !first {
+			if err := bw.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		first = false
+		data, err := json.Marshal(jsonEntry[K, V]{Key: group.Key, Values: group.Value})
+		if err != nil {
+			return err
+		}
+		if _, err = bw.Write(data); err != nil {
+			return err
+		}
+	}
+	if _, err := bw.WriteString("]\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// DecodeJSON reads a document written by EncodeJSON and builds a tree of
+// minimal height from it. Entries may come in any order; entries sharing a
+// key are merged in document order. Failures tied to an entry, such as a
+// duplicate key in a unique tree, are returned wrapped in ErrEntry.
+func DecodeJSON[K any, V any](r io.Reader, unique bool, creationSize int, comparer bst.Comparer[K, V]) (bst.BST[K, V], error) {
+	type positioned struct {
+		jsonEntry[K, V]
+		position int
+	}
+
+	dec := json.NewDecoder(r)
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('[') {
+		return nil, fmt.Errorf("expected a JSON array, found %v", token)
+	}
+	entries := make([]positioned, 0, 64)
+	for position := 0; dec.More(); position++ {
+		entry := positioned{position: position}
+		if err = dec.Decode(&entry.jsonEntry); err != nil {
+			return nil, ErrEntry{Position: position, Err: err}
+		}
+		switch {
+		case len(entry.Values) == 0:
+			return nil, ErrEntry{Position: position, Err: bst.ErrInvalidTree{
+				Key: entry.Key, Invariant: bst.InvariantValues, Detail: "no values",
+			}}
+		case unique && len(entry.Values) > 1:
+			return nil, ErrEntry{Position: position, Err: bst.ErrUniqueViolated{Key: entry.Key}}
+		}
+		entries = append(entries, entry)
+	}
+	if _, err = dec.Token(); err != nil {
+		return nil, err
+	}
+
+	slices.SortStableFunc(entries, func(a, b positioned) int {
+		comparison, compErr := comparer.CompareKeys(a.Key, b.Key)
+		if compErr != nil && err == nil {
+			err = compErr
+		}
+		return comparison
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	root := NewBST(unique, creationSize, comparer).(*Root[K, V])
+	groups := make([]bst.Entry[K, []V], 0, len(entries))
+	for _, entry := range entries {
+		if len(groups) > 0 {
+			last := &groups[len(groups)-1]
+			comparison, err := comparer.CompareKeys(last.Key, entry.Key)
+			if err != nil {
+				return nil, err
+			}
+			if comparison == 0 {
+				if unique {
+					return nil, ErrEntry{Position: entry.position, Err: bst.ErrUniqueViolated{Key: entry.Key}}
+				}
+				last.Value = append(last.Value, entry.Values...)
+				continue
+			}
+		}
+		groups = append(groups, bst.Entry[K, []V]{Key: entry.Key, Value: entry.Values})
+	}
+	root.build(groups, nil)
+	return root, nil
+}
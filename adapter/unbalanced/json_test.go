@@ -0,0 +1,116 @@
+package unbalanced_test
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/avl"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/unbalanced"
+)
+
+func (s *BSTTestSuite) TestEncodeJSON() {
+	b := unbalanced.NewBST(false, 0, comparer.NewComparer[string, int]())
+	s.NoError(b.Insert("b", 2))
+	s.NoError(b.Insert("a", 1))
+	s.NoError(b.Insert("c", 3))
+	s.NoError(b.Insert("a", 4))
+
+	expected := `[{"key":"a","values":[1,4]},{"key":"b","values":[2]},{"key":"c","values":[3]}]` + "\n"
+
+	var buf bytes.Buffer
+	s.NoError(unbalanced.EncodeJSON(&buf, b))
+	s.Equal(expected, buf.String())
+
+	// trees without bst.EntryIterator cannot be exported
+	buf.Reset()
+	s.Equal(unbalanced.ErrNoEntries{}, unbalanced.EncodeJSON(&buf, struct{ bst.BST[string, int] }{b}))
+	s.Zero(buf.Len())
+
+	// any adapter can be exported
+	a := avl.NewBST(false, 0, comparer.NewComparer[string, int]())
+	s.NoError(a.Insert("c", 3))
+	s.NoError(a.Insert("b", 2))
+	s.NoError(a.Insert("a", 1))
+	s.NoError(a.Insert("a", 4))
+	buf.Reset()
+	s.NoError(unbalanced.EncodeJSON(&buf, a))
+	s.Equal(expected, buf.String())
+
+	buf.Reset()
+	s.NoError(unbalanced.EncodeJSON(&buf, unbalanced.NewBST(false, 0, comparer.NewComparer[string, int]())))
+	s.Equal("[]\n", buf.String())
+}
+
+func (s *BSTTestSuite) TestDecodeJSON() {
+	var buf bytes.Buffer
+	s.NoError(unbalanced.EncodeJSON(&buf, s.b))
+	encoded := buf.String()
+
+	decoded, err := unbalanced.DecodeJSON(&buf, false, 0, comparer.NewComparer[string, int]())
+	s.NoError(err)
+	b := decoded.(*unbalanced.Root[string, int])
+	s.NoError(b.Validate())
+	s.Equal(s.collect(s.b.Groups()), s.collect(b.Groups()))
+	s.Equal(4, height(b.GetMin()))
+
+	buf.Reset()
+	s.NoError(unbalanced.EncodeJSON(&buf, b))
+	s.Equal(encoded, buf.String())
+
+	// hand-patched documents may be unordered and repeat keys
+	decoded, err = unbalanced.DecodeJSON(strings.NewReader(`[
+		{"key": "c", "values": [3]},
+		{"key": "a", "values": [1]},
+		{"key": "b", "values": [2]},
+		{"key": "a", "values": [4, 5]}
+	]`), false, 0, comparer.NewComparer[string, int]())
+	s.NoError(err)
+	s.NoError(decoded.(*unbalanced.Root[string, int]).Validate())
+	s.Equal([]bst.Entry[string, []int]{
+		{Key: "a", Value: []int{1, 4, 5}},
+		{Key: "b", Value: []int{2}},
+		{Key: "c", Value: []int{3}},
+	}, s.collect(decoded.(*unbalanced.Root[string, int]).Groups()))
+
+	decoded, err = unbalanced.DecodeJSON(strings.NewReader(`[]`), true, 0, comparer.NewComparer[string, int]())
+	s.NoError(err)
+	s.Equal(0, decoded.GetNumberOfKeys())
+	s.NoError(decoded.Insert("a", 1))
+	s.ErrorAs(decoded.Insert("a", 2), &bst.ErrUniqueViolated{})
+}
+
+func (s *BSTTestSuite) TestDecodeJSONErrors() {
+	decode := func(doc string, unique bool) error {
+		_, err := unbalanced.DecodeJSON(strings.NewReader(doc), unique, 0, comparer.NewComparer[string, int]())
+		return err
+	}
+
+	var entry unbalanced.ErrEntry
+
+	// duplicate keys in a unique tree
+	err := decode(`[{"key": "b", "values": [1]}, {"key": "a", "values": [2]}, {"key": "b", "values": [3]}]`, true)
+	s.ErrorAs(err, &bst.ErrUniqueViolated{})
+	s.ErrorAs(err, &entry)
+	s.Equal(2, entry.Position)
+	s.EqualError(err, "entry 2: constraint violated: b is not unique")
+
+	err = decode(`[{"key": "a", "values": [1, 2]}]`, true)
+	s.ErrorAs(err, &bst.ErrUniqueViolated{})
+	s.ErrorAs(err, &entry)
+	s.Equal(0, entry.Position)
+
+	// empty entries
+	err = decode(`[{"key": "a", "values": [1]}, {"key": "b", "values": []}]`, false)
+	s.ErrorAs(err, &bst.ErrInvalidTree{})
+	s.ErrorAs(err, &entry)
+	s.Equal(1, entry.Position)
+
+	// malformed documents
+	s.ErrorAs(decode(`[{"key": "a", "values": [1]}, {"key": 1, "values": [2]}]`, false), &entry)
+	s.Equal(1, entry.Position)
+	s.Error(decode(`{"key": "a", "values": [1]}`, false))
+	s.Error(decode(`[{"key": "a", "values": [1]}`, false))
+	s.Error(decode(``, false))
+}
@@ -2,6 +2,7 @@
 package bst
 
 import (
+	"context"
 	"fmt"
 	"iter"
 )
@@ -15,6 +16,14 @@ func (e ErrUniqueViolated) Error() string {
 	return fmt.Sprintf("constraint violated: %v is not unique", e.Key)
 }
 
+// ErrConcurrentModification is yielded by an iterator that found the tree
+// modified since it started, after which it stops.
+type ErrConcurrentModification struct{}
+
+func (e ErrConcurrentModification) Error() string {
+	return "tree modified during iteration"
+}
+
 // Bound TODO
 type Bound[K any] struct {
 	Value        K
@@ -22,12 +31,71 @@ type Bound[K any] struct {
 }
 
 // Query TODO
+//
+// If Equal is set, the query matches that key only and the bounds are
+// ignored: trees answer it with a single Search.
 type Query[K any] struct {
 	GreaterThan *Bound[K]
 	LowerThan   *Bound[K]
+	Equal       *K
+}
+
+// Eq returns a query matching key only.
+func Eq[K any](key K) Query[K] {
+	return Query[K]{Equal: &key}
+}
+
+// Gt returns a query matching the keys greater than key.
+func Gt[K any](key K) Query[K] {
+	return Query[K]{GreaterThan: &Bound[K]{Value: key}}
+}
+
+// Gte returns a query matching the keys greater than or equal to key.
+func Gte[K any](key K) Query[K] {
+	return Query[K]{GreaterThan: &Bound[K]{Value: key, IncludeEqual: true}}
+}
+
+// Lt returns a query matching the keys lower than key.
+func Lt[K any](key K) Query[K] {
+	return Query[K]{LowerThan: &Bound[K]{Value: key}}
+}
+
+// Lte returns a query matching the keys lower than or equal to key.
+func Lte[K any](key K) Query[K] {
+	return Query[K]{LowerThan: &Bound[K]{Value: key, IncludeEqual: true}}
+}
+
+// Range returns a query matching the keys from lower, included, up to upper,
+// excluded.
+func Range[K any](lower, upper K) Query[K] {
+	return Query[K]{
+		GreaterThan: &Bound[K]{Value: lower, IncludeEqual: true},
+		LowerThan:   &Bound[K]{Value: upper},
+	}
+}
+
+// QuerySet matches every key equal to one of Keys or satisfying one of
+// Ranges, or every key at all if All is set, except for the keys equal to one
+// of ExceptKeys or satisfying one of ExceptRanges. Keys and ranges may come in
+// any order, repeat and overlap: every matching key is yielded once, in
+// ascending order. As in a Query, ranges without bounds match no key.
+//
+// For instance, "key != x" is QuerySet{All: true, ExceptKeys: []K{x}}.
+type QuerySet[K any] struct {
+	Keys   []K
+	Ranges []Query[K]
+	All    bool
+
+	ExceptKeys   []K
+	ExceptRanges []Query[K]
 }
 
 // BST TODO
+//
+// Nodes returned by Search, GetMin and GetMax show the tree as it was when
+// they were returned. Writes may replace nodes instead of modifying them, as
+// persistent trees do, so search again after writing rather than reading a
+// node obtained before. GetMin and GetMax return nil if the tree is empty.
 type BST[K any, V any] interface {
 	Insert(key K, value V) error
 
@@ -43,6 +111,97 @@ type BST[K any, V any] interface {
 	Delete(key K, value *V) error
 }
 
+// Counter is implemented by trees that keep track of how many values they
+// hold. In unique trees it matches GetNumberOfKeys.
+type Counter interface {
+	GetNumberOfValues() int
+}
+
+// Descender is implemented by trees that can also be walked from the
+// greatest key down. GetAllDesc and QueryDesc yield the same values as GetAll
+// and Query, in reverse order.
+type Descender[K any, V any] interface {
+	GetAllDesc() iter.Seq[V]
+	QueryDesc(query Query[K]) iter.Seq2[V, error]
+}
+
+// ContextQuerier is implemented by trees whose scans can be cancelled.
+// QueryContext and GetAllContext yield the same values as Query and GetAll,
+// unless ctx is done before they end: then they yield ctx.Err() and stop.
+type ContextQuerier[K any, V any] interface {
+	QueryContext(ctx context.Context, query Query[K]) iter.Seq2[V, error]
+	GetAllContext(ctx context.Context) iter.Seq2[V, error]
+}
+
+// SetQuerier is implemented by trees answering a QuerySet with a single
+// traversal, descending only into subtrees that may hold matching keys.
+type SetQuerier[K any, V any] interface {
+	QueryIn(set QuerySet[K]) iter.Seq2[V, error]
+}
+
+// RangeFunc locates key relative to a range of keys which are contiguous in
+// the order of a tree: it returns a negative number if key comes before the
+// range, a positive one if it comes after it, and zero if key is inside it.
+// It describes ranges whose bounds cannot be expressed as keys, such as the
+// ones over a subset of the fields of composite keys.
+type RangeFunc[K any] func(key K) (int, error)
+
+// RangeQuerier is implemented by trees able to yield, in ascending key order,
+// the values of the keys located inside a range by a RangeFunc, descending
+// only into the subtrees that may hold them.
+type RangeQuerier[K any, V any] interface {
+	QueryRange(locate RangeFunc[K]) iter.Seq2[V, error]
+}
+
+// Nearest is implemented by trees able to find the key closest to another one,
+// which they may not hold, in a single descent from the root. The flag
+// returned by every method reports whether a matching key exists; when it does
+// not, the node is nil.
+type Nearest[K any, V any] interface {
+	// Floor returns the node holding the greatest key lower than or equal to
+	// key.
+	Floor(key K) (*Node[K, V], bool, error)
+	// Ceiling returns the node holding the lowest key greater than or equal to
+	// key.
+	Ceiling(key K) (*Node[K, V], bool, error)
+	// Predecessor returns the node holding the greatest key lower than key.
+	Predecessor(key K) (*Node[K, V], bool, error)
+	// Successor returns the node holding the lowest key greater than key.
+	Successor(key K) (*Node[K, V], bool, error)
+}
+
+// Entry pairs a value with the key it is stored under.
+type Entry[K any, V any] struct {
+	Key   K
+	Value V
+}
+
+// EntryIterator is implemented by trees that can yield keys alongside their
+// values, in ascending key order. GroupsContext yields the same groups as
+// Groups, unless ctx is done before it ends: then it yields ctx.Err() and
+// stops, as ContextQuerier does. The slices yielded by Groups and
+// GroupsContext and the nodes yielded by QueryNodes belong to the tree and
+// must not be modified.
+type EntryIterator[K any, V any] interface {
+	All() iter.Seq2[K, V]
+	Groups() iter.Seq2[K, []V]
+	GroupsContext(ctx context.Context) iter.Seq2[Entry[K, []V], error]
+	QueryEntries(query Query[K]) iter.Seq2[Entry[K, V], error]
+	QueryNodes(query Query[K]) iter.Seq2[*Node[K, V], error]
+}
+
+// OrderStatistic is implemented by trees that can locate keys by their
+// position. Positions count keys, not values, and start at zero.
+//
+// Rank returns how many keys are lower than key. Select returns the node at
+// position k, or nil if k is out of range. CountRange returns how many keys
+// match query.
+type OrderStatistic[K any, V any] interface {
+	Rank(key K) (int, error)
+	Select(k int) *Node[K, V]
+	CountRange(query Query[K]) (int, error)
+}
+
 // Node TODO
 type Node[K any, V any] struct {
 	Values  []V
@@ -57,3 +216,10 @@ type Comparer[K any, V any] interface {
 	CompareKeys(a K, b K) (int, error)
 	CompareValues(a V, b V) (bool, error)
 }
+
+// Codec converts values of type T to and from bytes, so that trees holding
+// them can be persisted.
+type Codec[T any] interface {
+	Encode(v T) ([]byte, error)
+	Decode(data []byte) (T, error)
+}
@@ -0,0 +1,1324 @@
+// Package bsttest provides a conformance suite for bst.BST implementations.
+// It checks that an adapter behaves like adapter/unbalanced.Root, the
+// reference implementation, so that adapters can be swapped without touching
+// call sites.
+//
+// Adapters run it from their own tests:
+//
+//	func TestConformance(t *testing.T) {
+//		bsttest.Run(t, func(unique bool, comparer bst.Comparer[string, int]) bst.BST[string, int] {
+//			return mypackage.NewBST(unique, 0, comparer)
+//		})
+//	}
+//
+// Suite can also be embedded in an adapter's own suite to add
+// implementation-specific tests next to the shared ones.
+package bsttest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+	"github.com/vinicius-lino-figueiredo/bst"
+	"github.com/vinicius-lino-figueiredo/bst/adapter/comparer"
+)
+
+// Factory creates an empty tree using the given uniqueness and comparer.
+type Factory[K any, V any] func(unique bool, comparer bst.Comparer[K, V]) bst.BST[K, V]
+
+// Run runs Suite against the trees created by factory.
+func Run(t *testing.T, factory Factory[string, int]) {
+	suite.Run(t, NewSuite(factory))
+}
+
+// NewSuite creates a Suite for the trees created by factory.
+func NewSuite(factory Factory[string, int]) *Suite {
+	return &Suite{NewBST: factory}
+}
+
+// Suite exercises every method of bst.BST. Before each test, Tree holds a
+// non-unique tree filled with the same fixture used by the reference tests.
+// Trees implementing bst.Validator are validated after being modified.
+type Suite struct {
+	suite.Suite
+	NewBST Factory[string, int]
+	Tree   bst.BST[string, int]
+}
+
+// SetupTest fills Tree with the fixture.
+func (s *Suite) SetupTest() {
+	s.Tree = s.NewBST(false, comparer.NewComparer[string, int]())
+
+	s.Require().NoError(s.Tree.Insert("Leo", 76))
+	s.Require().NoError(s.Tree.Insert("Alice", 42))
+	s.Require().NoError(s.Tree.Insert("Marcus", 87))
+	s.Require().NoError(s.Tree.Insert("Luna", 15))
+	s.Require().NoError(s.Tree.Insert("Felix", 63))
+	s.Require().NoError(s.Tree.Insert("Nina", 91))
+	s.Require().NoError(s.Tree.Insert("Oscar", 28))
+	s.Require().NoError(s.Tree.Insert("Maya", 54))
+	s.Require().NoError(s.Tree.Insert("Alice", 23))
+	s.Require().NoError(s.Tree.Insert("Iris", 33))
+	s.Require().NoError(s.Tree.Insert("Hugo", 88))
+	s.Require().NoError(s.Tree.Insert("Zara", 19))
+	s.Require().NoError(s.Tree.Insert("Felix", 55))
+	s.Require().NoError(s.Tree.Insert("Kai", 45))
+	s.Require().NoError(s.Tree.Insert("Nora", 67))
+	s.Require().NoError(s.Tree.Insert("Theo", 92))
+	s.Require().NoError(s.Tree.Insert("Luna", 38))
+	s.Require().NoError(s.Tree.Insert("Mila", 11))
+	s.Require().NoError(s.Tree.Insert("Oscar", 72))
+	s.Require().NoError(s.Tree.Insert("Maya", 49))
+}
+
+// fixture lists the contents of Tree after SetupTest, in key order.
+var fixture = []struct {
+	key    string
+	values []int
+}{
+	{"Alice", []int{42, 23}},
+	{"Felix", []int{63, 55}},
+	{"Hugo", []int{88}},
+	{"Iris", []int{33}},
+	{"Kai", []int{45}},
+	{"Leo", []int{76}},
+	{"Luna", []int{15, 38}},
+	{"Marcus", []int{87}},
+	{"Maya", []int{54, 49}},
+	{"Mila", []int{11}},
+	{"Nina", []int{91}},
+	{"Nora", []int{67}},
+	{"Oscar", []int{28, 72}},
+	{"Theo", []int{92}},
+	{"Zara", []int{19}},
+}
+
+func (s *Suite) TestInsertUnique() {
+	b := s.NewBST(true, comparer.NewComparer[string, int]())
+
+	s.NoError(b.Insert("unique", 10))
+	s.NoError(b.Insert("other", 11))
+	s.ErrorAs(b.Insert("unique", 12), &bst.ErrUniqueViolated{})
+
+	node, err := b.Search("unique")
+	s.NoError(err)
+	s.Equal([]int{10}, node.Values)
+	s.Equal(2, b.GetNumberOfKeys())
+	s.validate(b)
+}
+
+func (s *Suite) TestInsertNonUnique() {
+	b := s.NewBST(false, comparer.NewComparer[string, int]())
+
+	s.NoError(b.Insert("key", 1))
+	s.NoError(b.Insert("key", 2))
+	s.NoError(b.Insert("key", 1))
+
+	node, err := b.Search("key")
+	s.NoError(err)
+	s.Equal([]int{1, 2, 1}, node.Values)
+	s.Equal(1, b.GetNumberOfKeys())
+}
+
+func (s *Suite) TestInsertSorted() {
+	b := s.NewBST(true, comparer.NewComparer[string, int]())
+
+	for i := range 512 {
+		s.NoError(b.Insert(fmt.Sprintf("%04d", i), i))
+	}
+	for i := 1023; i >= 512; i-- {
+		s.NoError(b.Insert(fmt.Sprintf("%04d", i), i))
+	}
+
+	s.Equal(1024, b.GetNumberOfKeys())
+	s.Equal(s.count(1024), s.collect(b.GetAll()))
+	s.validate(b)
+}
+
+func (s *Suite) TestSearch() {
+	for _, entry := range fixture {
+		node, err := s.Tree.Search(entry.key)
+		s.NoError(err)
+		s.Require().NotNil(node, entry.key)
+		s.Equal(entry.key, node.Key)
+		s.Equal(entry.values, node.Values)
+	}
+
+	node, err := s.Tree.Search("Invalid")
+	s.NoError(err)
+	s.Nil(node)
+}
+
+func (s *Suite) TestSearchEmpty() {
+	b := s.NewBST(false, comparer.NewComparer[string, int]())
+
+	node, err := b.Search("any")
+	s.NoError(err)
+	s.Nil(node)
+}
+
+func (s *Suite) TestSimpleQuery() {
+	s.assertQuery([]int{}, nil, &bst.Bound[string]{Value: "Zara", IncludeEqual: false})
+	s.assertQuery([]int{19}, nil, &bst.Bound[string]{Value: "Zara", IncludeEqual: true})
+	s.assertQuery([]int{92, 19}, nil, &bst.Bound[string]{Value: "Theo", IncludeEqual: true})
+	s.assertQuery([]int{19}, nil, &bst.Bound[string]{Value: "Theo", IncludeEqual: false})
+	s.assertQuery([]int{92, 19}, nil, &bst.Bound[string]{Value: "Tarzan", IncludeEqual: false})
+	s.assertQuery(s.all(), nil, &bst.Bound[string]{Value: "Aaron", IncludeEqual: false})
+
+	s.assertQuery([]int{}, &bst.Bound[string]{Value: "Alice", IncludeEqual: false}, nil)
+	s.assertQuery([]int{42, 23}, &bst.Bound[string]{Value: "Alice", IncludeEqual: true}, nil)
+	s.assertQuery([]int{42, 23, 63, 55}, &bst.Bound[string]{Value: "Felix", IncludeEqual: true}, nil)
+	s.assertQuery([]int{42, 23}, &bst.Bound[string]{Value: "Felix", IncludeEqual: false}, nil)
+	s.assertQuery([]int{42, 23}, &bst.Bound[string]{Value: "Dave", IncludeEqual: false}, nil)
+	s.assertQuery(s.all(), &bst.Bound[string]{Value: "Zhonya", IncludeEqual: false}, nil)
+}
+
+func (s *Suite) TestMutualExcludingQueryBounds() {
+	// bounds that cancel each other out
+	for _, lowerEqual := range []bool{false, true} {
+		for _, greaterEqual := range []bool{false, true} {
+			s.assertQuery([]int{},
+				&bst.Bound[string]{Value: "Iris", IncludeEqual: lowerEqual},
+				&bst.Bound[string]{Value: "Zara", IncludeEqual: greaterEqual},
+			)
+		}
+	}
+
+	// same value, non-inclusive query
+	s.assertQuery([]int{},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: false},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: false},
+	)
+	s.assertQuery([]int{},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: true},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: false},
+	)
+	s.assertQuery([]int{},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: false},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: true},
+	)
+
+	// same value, inclusive query
+	s.assertQuery([]int{33},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: true},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: true},
+	)
+
+	// same missing value
+	s.assertQuery([]int{},
+		&bst.Bound[string]{Value: "Jack", IncludeEqual: true},
+		&bst.Bound[string]{Value: "Jack", IncludeEqual: true},
+	)
+}
+
+func (s *Suite) TestQuery() {
+	s.assertQuery([]int{63, 55, 88},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: false},
+		&bst.Bound[string]{Value: "Alice", IncludeEqual: false},
+	)
+	s.assertQuery([]int{63, 55, 88, 33},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: true},
+		&bst.Bound[string]{Value: "Alice", IncludeEqual: false},
+	)
+	s.assertQuery([]int{42, 23, 63, 55, 88},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: false},
+		&bst.Bound[string]{Value: "Alice", IncludeEqual: true},
+	)
+	s.assertQuery([]int{42, 23, 63, 55, 88, 33},
+		&bst.Bound[string]{Value: "Iris", IncludeEqual: true},
+		&bst.Bound[string]{Value: "Alice", IncludeEqual: true},
+	)
+
+	// bounds between keys
+	s.assertQuery([]int{33, 45, 76, 15, 38},
+		&bst.Bound[string]{Value: "Lux", IncludeEqual: false},
+		&bst.Bound[string]{Value: "Igor", IncludeEqual: true},
+	)
+	s.assertQuery(s.all(),
+		&bst.Bound[string]{Value: "Zz", IncludeEqual: false},
+		&bst.Bound[string]{Value: "A", IncludeEqual: false},
+	)
+}
+
+func (s *Suite) TestQueryHelpers() {
+	for _, entry := range fixture {
+		data, err := s.fetch(s.Tree.Query(bst.Eq(entry.key)))
+		s.NoError(err)
+		s.Equal(entry.values, data, entry.key)
+	}
+	data, err := s.fetch(s.Tree.Query(bst.Eq("Lux")))
+	s.NoError(err)
+	s.Empty(data)
+
+	// bounds are ignored next to Equal
+	query := bst.Eq("Hugo")
+	query.LowerThan = &bst.Bound[string]{Value: "A"}
+	data, err = s.fetch(s.Tree.Query(query))
+	s.NoError(err)
+	s.Equal([]int{88}, data)
+
+	for _, c := range []struct {
+		query    bst.Query[string]
+		expected []int
+	}{
+		{bst.Gt("Oscar"), []int{92, 19}},
+		{bst.Gte("Oscar"), []int{28, 72, 92, 19}},
+		{bst.Lt("Felix"), []int{42, 23}},
+		{bst.Lte("Felix"), []int{42, 23, 63, 55}},
+		{bst.Range("Iris", "Leo"), []int{33, 45}},
+		{bst.Range("Leo", "Iris"), []int{}},
+	} {
+		data, err := s.fetch(s.Tree.Query(c.query))
+		s.NoError(err)
+		s.Equal(c.expected, data, "%s", describe(c.query))
+	}
+
+	b := s.NewBST(false, &failingComparer{failKeys: true})
+	s.NoError(b.Insert("a", 1))
+	_, err = s.fetch(b.Query(bst.Eq("a")))
+	s.ErrorIs(err, errComparison)
+}
+
+func (s *Suite) TestQueryEmpty() {
+	b := s.NewBST(false, comparer.NewComparer[string, int]())
+
+	data, err := s.fetch(b.Query(bst.Query[string]{
+		GreaterThan: &bst.Bound[string]{Value: "A", IncludeEqual: true},
+		LowerThan:   &bst.Bound[string]{Value: "Z", IncludeEqual: true},
+	}))
+	s.NoError(err)
+	s.Empty(data)
+}
+
+func (s *Suite) TestQueryStop() {
+	query := bst.Query[string]{
+		GreaterThan: &bst.Bound[string]{Value: "Felix", IncludeEqual: true},
+		LowerThan:   &bst.Bound[string]{Value: "Oscar", IncludeEqual: true},
+	}
+	data := make([]int, 0, 3)
+	for v, err := range s.Tree.Query(query) {
+		s.NoError(err)
+		data = append(data, v)
+		if len(data) == 3 {
+			break
+		}
+	}
+	s.Equal([]int{63, 55, 88}, data)
+}
+
+func (s *Suite) TestQueryError() {
+	b := s.NewBST(false, &failingComparer{})
+	s.NoError(b.Insert("a", 1))
+
+	_, err := s.fetch(b.Query(bst.Query[string]{
+		GreaterThan: &bst.Bound[string]{Value: "0", IncludeEqual: true},
+	}))
+	s.ErrorIs(err, errComparison)
+	_, err = s.fetch(b.Query(bst.Query[string]{
+		LowerThan: &bst.Bound[string]{Value: "z", IncludeEqual: true},
+	}))
+	s.ErrorIs(err, errComparison)
+	_, err = s.fetch(b.Query(bst.Query[string]{
+		GreaterThan: &bst.Bound[string]{Value: "0", IncludeEqual: true},
+		LowerThan:   &bst.Bound[string]{Value: "z", IncludeEqual: true},
+	}))
+	s.ErrorIs(err, errComparison)
+}
+
+func (s *Suite) TestComparerErrors() {
+	c := &failingComparer{}
+	b := s.NewBST(false, c)
+	s.NoError(b.Insert("a", 1))
+
+	c.failKeys = true
+	s.ErrorIs(b.Insert("b", 2), errComparison)
+	_, err := b.Search("a")
+	s.ErrorIs(err, errComparison)
+	s.ErrorIs(b.Delete("a", nil), errComparison)
+	s.ErrorIs(b.Update("a", 1, 2), errComparison)
+
+	c.failKeys, c.failValues = false, true
+	value := 1
+	s.ErrorIs(b.Delete("a", &value), errComparison)
+	s.ErrorIs(b.Update("a", 1, 2), errComparison)
+
+	c.failValues = false
+	node, err := b.Search("a")
+	s.NoError(err)
+	s.Equal([]int{1}, node.Values)
+	s.Equal(1, b.GetNumberOfKeys())
+}
+
+func (s *Suite) TestDeleteValue() {
+	s.NoError(s.Tree.Insert("Leo", 77))
+
+	// Remove a single item
+	value := 63
+	s.NoError(s.Tree.Delete("Felix", &value))
+
+	// Key still exists, but the deleted value is gone
+	node, err := s.Tree.Search("Felix")
+	s.NoError(err)
+	s.Equal([]int{55}, node.Values)
+	s.Equal(15, s.Tree.GetNumberOfKeys())
+
+	// Deleting the other value removes the emptied node too
+	value = 55
+	s.NoError(s.Tree.Delete("Felix", &value))
+	node, err = s.Tree.Search("Felix")
+	s.NoError(err)
+	s.Nil(node)
+	s.Equal(14, s.Tree.GetNumberOfKeys())
+
+	// Deleting a missing value keeps the node untouched
+	value = 1
+	s.NoError(s.Tree.Delete("Leo", &value))
+	node, err = s.Tree.Search("Leo")
+	s.NoError(err)
+	s.Equal([]int{76, 77}, node.Values)
+	s.Equal(14, s.Tree.GetNumberOfKeys())
+
+	// Only the first equal value is removed
+	s.NoError(s.Tree.Insert("Leo", 76))
+	value = 76
+	s.NoError(s.Tree.Delete("Leo", &value))
+	node, err = s.Tree.Search("Leo")
+	s.NoError(err)
+	s.Equal([]int{77, 76}, node.Values)
+	s.validate(s.Tree)
+}
+
+func (s *Suite) TestDeleteNode() {
+	// Deleting without a value removes the whole node
+	s.NoError(s.Tree.Delete("Maya", nil))
+	node, err := s.Tree.Search("Maya")
+	s.NoError(err)
+	s.Nil(node)
+	s.Equal(14, s.Tree.GetNumberOfKeys())
+
+	// Deleting a missing key is a no-op
+	s.NoError(s.Tree.Delete("Invalid", nil))
+	s.Equal(14, s.Tree.GetNumberOfKeys())
+
+	s.Equal([]int{42, 23, 63, 55, 88, 33, 45, 76, 15, 38, 87, 11, 91, 67, 28, 72, 92, 19},
+		s.collect(s.Tree.GetAll()))
+
+	for _, entry := range fixture {
+		if entry.key == "Maya" {
+			continue
+		}
+		node, err := s.Tree.Search(entry.key)
+		s.NoError(err)
+		s.Require().NotNil(node, entry.key)
+		s.Equal(entry.values, node.Values)
+	}
+	s.validate(s.Tree)
+}
+
+func (s *Suite) TestDeleteRoot() {
+	b := s.NewBST(false, comparer.NewComparer[string, int]())
+
+	s.NoError(b.Insert("b", 2))
+	s.NoError(b.Insert("a", 1))
+	s.NoError(b.Insert("c", 3))
+
+	s.NoError(b.Delete("b", nil))
+	s.Equal([]int{1, 3}, s.collect(b.GetAll()))
+	s.Equal("a", b.GetMin().Key)
+	s.Equal("c", b.GetMax().Key)
+	s.Equal(2, b.GetNumberOfKeys())
+	s.validate(b)
+}
+
+func (s *Suite) TestDeleteAll() {
+	b := s.NewBST(true, comparer.NewComparer[string, int]())
+
+	for i := range 1000 {
+		n := (i * 7919) % 1000
+		s.NoError(b.Insert(fmt.Sprintf("%04d", n), n))
+	}
+	s.Equal(s.count(1000), s.collect(b.GetAll()))
+
+	deleted := make(map[int]bool, 1000)
+	for i := range 1000 {
+		n := (i * 104729) % 1000
+		s.NoError(b.Delete(fmt.Sprintf("%04d", n), nil))
+		deleted[n] = true
+		s.Equal(999-i, b.GetNumberOfKeys())
+
+		if i%97 == 0 {
+			expected := make([]int, 0, 999-i)
+			for _, v := range s.count(1000) {
+				if !deleted[v] {
+					expected = append(expected, v)
+				}
+			}
+			s.Equal(expected, s.collect(b.GetAll()))
+			s.validate(b)
+		}
+	}
+	s.Empty(s.collect(b.GetAll()))
+	s.validate(b)
+
+	// the emptied tree is still usable
+	s.NoError(b.Insert("again", 1))
+	node, err := b.Search("again")
+	s.NoError(err)
+	s.Equal([]int{1}, node.Values)
+	s.Equal(1, b.GetNumberOfKeys())
+}
+
+func (s *Suite) TestUpdate() {
+	s.NoError(s.Tree.Update("Leo", 76, 10))
+
+	node, err := s.Tree.Search("Leo")
+	s.NoError(err)
+	s.Equal([]int{10}, node.Values)
+
+	// writes may replace the node returned by an earlier Search, so each
+	// check below searches again
+
+	// missing values and keys are ignored
+	s.NoError(s.Tree.Update("Leo", 12, 1000))
+	s.NoError(s.Tree.Update("Invalid", 12, 1000))
+	node, err = s.Tree.Search("Leo")
+	s.NoError(err)
+	s.Equal([]int{10}, node.Values)
+
+	// only the first equal value is replaced
+	s.NoError(s.Tree.Insert("Leo", 10))
+	s.NoError(s.Tree.Update("Leo", 10, 11))
+	node, err = s.Tree.Search("Leo")
+	s.NoError(err)
+	s.Equal([]int{11, 10}, node.Values)
+	s.Equal(15, s.Tree.GetNumberOfKeys())
+}
+
+func (s *Suite) TestGetMinMax() {
+	lowest := s.Tree.GetMin()
+	s.Equal("Alice", lowest.Key)
+	s.Equal([]int{42, 23}, lowest.Values)
+
+	greatest := s.Tree.GetMax()
+	s.Equal("Zara", greatest.Key)
+	s.Equal([]int{19}, greatest.Values)
+
+	s.NoError(s.Tree.Delete("Alice", nil))
+	s.NoError(s.Tree.Delete("Zara", nil))
+	s.Equal("Felix", s.Tree.GetMin().Key)
+	s.Equal("Theo", s.Tree.GetMax().Key)
+
+	// empty trees have neither
+	empty := s.NewBST(false, comparer.NewComparer[string, int]())
+	s.Nil(empty.GetMin())
+	s.Nil(empty.GetMax())
+	for _, entry := range fixture {
+		s.NoError(s.Tree.Delete(entry.key, nil))
+	}
+	s.Nil(s.Tree.GetMin())
+	s.Nil(s.Tree.GetMax())
+}
+
+func (s *Suite) TestGetAll() {
+	s.Equal(s.all(), s.collect(s.Tree.GetAll()))
+
+	data := make([]int, 0, 4)
+	for v := range s.Tree.GetAll() {
+		data = append(data, v)
+		if len(data) == 4 {
+			break
+		}
+	}
+	s.Equal([]int{42, 23, 63, 55}, data)
+
+	b := s.NewBST(false, comparer.NewComparer[string, int]())
+	s.Empty(s.collect(b.GetAll()))
+}
+
+func (s *Suite) TestGetNumberOfKeys() {
+	s.Equal(len(fixture), s.Tree.GetNumberOfKeys())
+
+	s.NoError(s.Tree.Insert("Alice", 1))
+	s.Equal(len(fixture), s.Tree.GetNumberOfKeys())
+
+	s.NoError(s.Tree.Insert("Bob", 1))
+	s.Equal(len(fixture)+1, s.Tree.GetNumberOfKeys())
+
+	b := s.NewBST(false, comparer.NewComparer[string, int]())
+	s.Equal(0, b.GetNumberOfKeys())
+}
+
+func (s *Suite) TestGetNumberOfValues() {
+	tree, ok := s.Tree.(bst.Counter)
+	if !ok {
+		s.T().Skip("tree does not implement bst.Counter")
+	}
+
+	s.Equal(20, tree.GetNumberOfValues())
+
+	s.NoError(s.Tree.Insert("Alice", 1))
+	s.NoError(s.Tree.Insert("Bob", 1))
+	s.Equal(22, tree.GetNumberOfValues())
+
+	value := 1
+	s.NoError(s.Tree.Delete("Alice", &value))
+	s.NoError(s.Tree.Delete("Alice", &value))
+	s.NoError(s.Tree.Delete("Invalid", &value))
+	s.Equal(21, tree.GetNumberOfValues())
+
+	s.NoError(s.Tree.Delete("Felix", nil))
+	s.NoError(s.Tree.Delete("Invalid", nil))
+	s.Equal(19, tree.GetNumberOfValues())
+
+	value = 1
+	s.NoError(s.Tree.Delete("Bob", &value))
+	s.Equal(18, tree.GetNumberOfValues())
+
+	s.NoError(s.Tree.Update("Luna", 15, 16))
+	s.Equal(18, tree.GetNumberOfValues())
+	s.validate(s.Tree)
+
+	b := s.NewBST(true, comparer.NewComparer[string, int]())
+	s.Equal(0, b.(bst.Counter).GetNumberOfValues())
+	s.NoError(b.Insert("a", 1))
+	s.Error(b.Insert("a", 2))
+	s.Equal(1, b.(bst.Counter).GetNumberOfValues())
+	s.NoError(b.Delete("a", nil))
+	s.Equal(0, b.(bst.Counter).GetNumberOfValues())
+
+	c := &failingComparer{}
+	b = s.NewBST(false, c)
+	s.NoError(b.Insert("a", 1))
+	s.NoError(b.Insert("a", 2))
+	c.failValues = true
+	s.Error(b.Delete("a", &value))
+	s.Equal(2, b.(bst.Counter).GetNumberOfValues())
+}
+
+func (s *Suite) TestDescending() {
+	tree, ok := s.Tree.(bst.Descender[string, int])
+	if !ok {
+		s.T().Skip("tree does not implement bst.Descender")
+	}
+
+	expected := s.all()
+	slices.Reverse(expected)
+	s.Equal(expected, s.collect(tree.GetAllDesc()))
+
+	for _, query := range s.queries() {
+		expected, err := s.fetch(s.Tree.Query(query))
+		s.NoError(err)
+		slices.Reverse(expected)
+		data, err := s.fetch(tree.QueryDesc(query))
+		s.NoError(err)
+		s.Equal(expected, data, "%s", describe(query))
+	}
+
+	// stopping early
+	data := make([]int, 0, 3)
+	for v, err := range tree.QueryDesc(bst.Query[string]{
+		LowerThan: &bst.Bound[string]{Value: "Oscar", IncludeEqual: false},
+	}) {
+		s.NoError(err)
+		data = append(data, v)
+		if len(data) == 3 {
+			break
+		}
+	}
+	s.Equal([]int{67, 91, 11}, data)
+
+	b := s.NewBST(false, &failingComparer{})
+	s.NoError(b.Insert("a", 1))
+	_, err := s.fetch(b.(bst.Descender[string, int]).QueryDesc(bst.Query[string]{
+		GreaterThan: &bst.Bound[string]{Value: "0", IncludeEqual: true},
+	}))
+	s.ErrorIs(err, errComparison)
+
+	b = s.NewBST(false, comparer.NewComparer[string, int]())
+	s.Empty(s.collect(b.(bst.Descender[string, int]).GetAllDesc()))
+}
+
+func (s *Suite) TestQueryIn() {
+	tree, ok := s.Tree.(bst.SetQuerier[string, int])
+	if !ok {
+		s.T().Skip("tree does not implement bst.SetQuerier")
+	}
+
+	queries := s.queries()
+	sets := []bst.QuerySet[string]{
+		{},
+		{Keys: []string{"Maya", "Alice", "Maya", "Bob", "Zara"}},
+		{Keys: []string{"Felix"}, Ranges: []bst.Query[string]{{}}},
+		{All: true},
+		{All: true, ExceptKeys: []string{"Maya", "Alice", "Zara"}},
+		{Keys: []string{"Maya"}, ExceptKeys: []string{"Maya"}},
+		{All: true, ExceptRanges: []bst.Query[string]{
+			{GreaterThan: &bst.Bound[string]{Value: "Hugo"}, LowerThan: &bst.Bound[string]{Value: "Nina", IncludeEqual: true}},
+		}},
+		{Keys: []string{"Kai", "Oscar"}, Ranges: []bst.Query[string]{
+			{GreaterThan: &bst.Bound[string]{Value: "Luna", IncludeEqual: true}, LowerThan: &bst.Bound[string]{Value: "Nina"}},
+			{GreaterThan: &bst.Bound[string]{Value: "Maya"}, LowerThan: &bst.Bound[string]{Value: "Nora", IncludeEqual: true}},
+			{LowerThan: &bst.Bound[string]{Value: "Felix"}},
+		}},
+	}
+	for i, query := range queries {
+		other := queries[(i*7)%len(queries)]
+		sets = append(sets,
+			bst.QuerySet[string]{Ranges: []bst.Query[string]{query}},
+			bst.QuerySet[string]{
+				Keys:   []string{fixture[i%len(fixture)].key, "Lux"},
+				Ranges: []bst.Query[string]{query, other},
+			},
+			bst.QuerySet[string]{All: true, ExceptRanges: []bst.Query[string]{query}},
+			bst.QuerySet[string]{
+				Ranges:       []bst.Query[string]{query},
+				ExceptKeys:   []string{fixture[i%len(fixture)].key, "Lux"},
+				ExceptRanges: []bst.Query[string]{other},
+			},
+			bst.QuerySet[string]{
+				Ranges:       []bst.Query[string]{query, other},
+				ExceptRanges: []bst.Query[string]{queries[(i*11)%len(queries)], queries[(i*13)%len(queries)]},
+			},
+		)
+	}
+	for _, set := range sets {
+		expected := make([]int, 0, 20)
+		for _, entry := range fixture {
+			if s.matchesSet(entry.key, set) {
+				expected = append(expected, entry.values...)
+			}
+		}
+		data, err := s.fetch(tree.QueryIn(set))
+		s.NoError(err)
+		s.Equal(expected, data, "%v", set)
+	}
+
+	// stopping early
+	data := make([]int, 0, 3)
+	for v, err := range tree.QueryIn(bst.QuerySet[string]{Keys: []string{"Zara", "Alice", "Hugo"}}) {
+		s.NoError(err)
+		data = append(data, v)
+		if len(data) == 3 {
+			break
+		}
+	}
+	s.Equal([]int{42, 23, 88}, data)
+
+	b := s.NewBST(false, &failingComparer{})
+	s.NoError(b.Insert("a", 1))
+	_, err := s.fetch(b.(bst.SetQuerier[string, int]).QueryIn(bst.QuerySet[string]{Keys: []string{"z"}}))
+	s.ErrorIs(err, errComparison)
+
+	// comparing the bounds of two ranges fails too
+	b = s.NewBST(false, &failingComparer{failPair: &[2]string{"h", "p"}})
+	for _, key := range []string{"a", "k", "t"} {
+		s.NoError(b.Insert(key, 1))
+	}
+	_, err = s.fetch(b.(bst.SetQuerier[string, int]).QueryIn(bst.QuerySet[string]{
+		Ranges:       []bst.Query[string]{bst.Lt("h")},
+		ExceptRanges: []bst.Query[string]{bst.Gt("p")},
+	}))
+	s.ErrorIs(err, errComparison)
+
+	b = s.NewBST(false, comparer.NewComparer[string, int]())
+	data, err = s.fetch(b.(bst.SetQuerier[string, int]).QueryIn(bst.QuerySet[string]{Keys: []string{"a"}}))
+	s.NoError(err)
+	s.Empty(data)
+}
+
+func (s *Suite) TestQueryRange() {
+	tree, ok := s.Tree.(bst.RangeQuerier[string, int])
+	if !ok {
+		s.T().Skip("tree does not implement bst.RangeQuerier")
+	}
+
+	for _, query := range s.queries() {
+		if query.Equal == nil && query.GreaterThan == nil && query.LowerThan == nil {
+			continue
+		}
+		expected, err := s.fetch(s.Tree.Query(query))
+		s.NoError(err)
+		data, err := s.fetch(tree.QueryRange(func(key string) (int, error) {
+			switch {
+			case s.matches(key, query):
+				return 0, nil
+			case query.Equal != nil:
+				return strings.Compare(key, *query.Equal), nil
+			case query.GreaterThan != nil && key <= query.GreaterThan.Value:
+				return -1, nil
+			}
+			return 1, nil
+		}))
+		s.NoError(err)
+		s.Equal(expected, data, "%s", describe(query))
+	}
+
+	// stopping early
+	data := make([]int, 0, 3)
+	for v, err := range tree.QueryRange(func(string) (int, error) { return 0, nil }) {
+		s.NoError(err)
+		data = append(data, v)
+		if len(data) == 3 {
+			break
+		}
+	}
+	s.Equal([]int{42, 23, 63}, data)
+
+	_, err := s.fetch(tree.QueryRange(func(key string) (int, error) {
+		if key == "Leo" {
+			return 0, errComparison
+		}
+		return strings.Compare(key, "Leo"), nil
+	}))
+	s.ErrorIs(err, errComparison)
+
+	b := s.NewBST(false, comparer.NewComparer[string, int]())
+	data, err = s.fetch(b.(bst.RangeQuerier[string, int]).QueryRange(func(string) (int, error) { return 0, nil }))
+	s.NoError(err)
+	s.Empty(data)
+}
+
+func (s *Suite) TestNearest() {
+	tree, ok := s.Tree.(bst.Nearest[string, int])
+	if !ok {
+		s.T().Skip("tree does not implement bst.Nearest")
+	}
+
+	keys := make([]string, 0, len(fixture))
+	for _, entry := range fixture {
+		keys = append(keys, entry.key)
+	}
+	// reference finds the first key of keys, walked in the given direction,
+	// that satisfies accept.
+	reference := func(desc bool, accept func(string) bool) (string, bool) {
+		ordered := slices.Clone(keys)
+		if desc {
+			slices.Reverse(ordered)
+		}
+		for _, key := range ordered {
+			if accept(key) {
+				return key, true
+			}
+		}
+		return "", false
+	}
+
+	probes := append(slices.Clone(keys), "A", "Bob", "Lux", "Mi", "Zz")
+	for _, probe := range probes {
+		for _, c := range []struct {
+			name   string
+			find   func(string) (*bst.Node[string, int], bool, error)
+			desc   bool
+			accept func(string) bool
+		}{
+			{"Floor", tree.Floor, true, func(k string) bool { return k <= probe }},
+			{"Ceiling", tree.Ceiling, false, func(k string) bool { return k >= probe }},
+			{"Predecessor", tree.Predecessor, true, func(k string) bool { return k < probe }},
+			{"Successor", tree.Successor, false, func(k string) bool { return k > probe }},
+		} {
+			node, found, err := c.find(probe)
+			s.NoError(err)
+			key, exists := reference(c.desc, c.accept)
+			s.Equal(exists, found, "%s(%s)", c.name, probe)
+			if !exists {
+				s.Nil(node, "%s(%s)", c.name, probe)
+				continue
+			}
+			s.Require().NotNil(node, "%s(%s)", c.name, probe)
+			s.Equal(key, node.Key, "%s(%s)", c.name, probe)
+			expected, err := s.Tree.Search(key)
+			s.NoError(err)
+			s.Equal(expected.Values, node.Values)
+		}
+	}
+
+	b := s.NewBST(false, comparer.NewComparer[string, int]())
+	node, found, err := b.(bst.Nearest[string, int]).Floor("a")
+	s.NoError(err)
+	s.False(found)
+	s.Nil(node)
+
+	b = s.NewBST(false, &failingComparer{failKeys: true})
+	s.NoError(b.Insert("a", 1))
+	_, found, err = b.(bst.Nearest[string, int]).Successor("a")
+	s.ErrorIs(err, errComparison)
+	s.False(found)
+}
+
+func (s *Suite) TestContext() {
+	tree, ok := s.Tree.(bst.ContextQuerier[string, int])
+	if !ok {
+		s.T().Skip("tree does not implement bst.ContextQuerier")
+	}
+
+	data, err := s.fetch(tree.GetAllContext(context.Background()))
+	s.NoError(err)
+	s.Equal(s.all(), data)
+	for _, query := range s.queries() {
+		expected, err := s.fetch(s.Tree.Query(query))
+		s.NoError(err)
+		data, err := s.fetch(tree.QueryContext(context.Background(), query))
+		s.NoError(err)
+		s.Equal(expected, data, "%s", describe(query))
+	}
+
+	// done contexts stop scans before they start
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	data, err = s.fetch(tree.GetAllContext(ctx))
+	s.ErrorIs(err, context.Canceled)
+	s.Empty(data)
+	data, err = s.fetch(tree.QueryContext(ctx, bst.Query[string]{
+		GreaterThan: &bst.Bound[string]{Value: "A", IncludeEqual: true},
+	}))
+	s.ErrorIs(err, context.Canceled)
+	s.Empty(data)
+
+	// and long ones midway
+	b := s.NewBST(true, comparer.NewComparer[string, int]())
+	for i := range 1000 {
+		s.NoError(b.Insert(fmt.Sprintf("%04d", i), i))
+	}
+	scans := map[string]func(context.Context) iter.Seq2[int, error]{
+		"GetAllContext": b.(bst.ContextQuerier[string, int]).GetAllContext,
+		"QueryContext": func(ctx context.Context) iter.Seq2[int, error] {
+			return b.(bst.ContextQuerier[string, int]).QueryContext(ctx, bst.Query[string]{
+				LowerThan: &bst.Bound[string]{Value: "9999"},
+			})
+		},
+	}
+	for name, scan := range scans {
+		ctx, cancel := context.WithCancel(context.Background())
+		data := make([]int, 0, 100)
+		var err error
+		for v, scanErr := range scan(ctx) {
+			if scanErr != nil {
+				err = scanErr
+				break
+			}
+			data = append(data, v)
+			if len(data) == 10 {
+				cancel()
+			}
+		}
+		cancel()
+		s.ErrorIs(err, context.Canceled, name)
+		s.Less(len(data), 1000, name)
+	}
+}
+
+func (s *Suite) TestEntries() {
+	tree, ok := s.Tree.(bst.EntryIterator[string, int])
+	if !ok {
+		s.T().Skip("tree does not implement bst.EntryIterator")
+	}
+
+	expected := make([]bst.Entry[string, int], 0, 20)
+	for _, entry := range fixture {
+		for _, v := range entry.values {
+			expected = append(expected, bst.Entry[string, int]{Key: entry.key, Value: v})
+		}
+	}
+	all := make([]bst.Entry[string, int], 0, 20)
+	for k, v := range tree.All() {
+		all = append(all, bst.Entry[string, int]{Key: k, Value: v})
+	}
+	s.Equal(expected, all)
+
+	i := 0
+	for k, values := range tree.Groups() {
+		s.Equal(fixture[i].key, k)
+		s.Equal(fixture[i].values, values)
+		i++
+	}
+	s.Equal(len(fixture), i)
+
+	i = 0
+	for group, err := range tree.GroupsContext(context.Background()) {
+		s.NoError(err)
+		s.Equal(fixture[i].key, group.Key)
+		s.Equal(fixture[i].values, group.Value)
+		i++
+	}
+	s.Equal(len(fixture), i)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var errs []error
+	for group, err := range tree.GroupsContext(ctx) {
+		s.Zero(group)
+		errs = append(errs, err)
+	}
+	s.Equal([]error{context.Canceled}, errs)
+
+	for _, query := range s.queries() {
+		values, err := s.fetch(s.Tree.Query(query))
+		s.NoError(err)
+
+		data := make([]int, 0, 20)
+		for entry, err := range tree.QueryEntries(query) {
+			s.NoError(err)
+			node, err := s.Tree.Search(entry.Key)
+			s.NoError(err)
+			s.Contains(node.Values, entry.Value)
+			data = append(data, entry.Value)
+		}
+		s.Equal(values, data, "%s", describe(query))
+
+		data = data[:0]
+		for node, err := range tree.QueryNodes(query) {
+			s.NoError(err)
+			data = append(data, node.Values...)
+		}
+		s.Equal(values, data, "%s", describe(query))
+	}
+
+	// stopping early
+	for k := range tree.All() {
+		s.Equal("Alice", k)
+		break
+	}
+	for k := range tree.Groups() {
+		s.Equal("Alice", k)
+		break
+	}
+	for entry, err := range tree.QueryEntries(bst.Query[string]{
+		GreaterThan: &bst.Bound[string]{Value: "Luna", IncludeEqual: true},
+	}) {
+		s.NoError(err)
+		s.Equal(bst.Entry[string, int]{Key: "Luna", Value: 15}, entry)
+		break
+	}
+
+	b := s.NewBST(false, &failingComparer{})
+	s.NoError(b.Insert("a", 1))
+	query := bst.Query[string]{LowerThan: &bst.Bound[string]{Value: "z", IncludeEqual: true}}
+	errs = nil
+	for _, err := range b.(bst.EntryIterator[string, int]).QueryEntries(query) {
+		errs = append(errs, err)
+	}
+	for node, err := range b.(bst.EntryIterator[string, int]).QueryNodes(query) {
+		s.Nil(node)
+		errs = append(errs, err)
+	}
+	s.Equal([]error{errComparison, errComparison}, errs)
+}
+
+func (s *Suite) TestCursor() {
+	tree, ok := s.Tree.(bst.Navigable[string, int])
+	if !ok {
+		s.T().Skip("tree does not implement bst.Navigable")
+	}
+
+	cursor := tree.Cursor()
+	s.False(cursor.Valid())
+	s.Equal("", cursor.Key())
+	s.Nil(cursor.Values())
+	s.False(cursor.Next())
+	s.False(cursor.Prev())
+
+	// forwards
+	s.True(cursor.First())
+	for i, entry := range fixture {
+		s.True(cursor.Valid())
+		s.Equal(entry.key, cursor.Key())
+		s.Equal(entry.values, cursor.Values())
+		s.Equal(i < len(fixture)-1, cursor.Next())
+	}
+	s.False(cursor.Valid())
+
+	// backwards
+	s.True(cursor.Last())
+	for i := len(fixture) - 1; i >= 0; i-- {
+		s.Equal(fixture[i].key, cursor.Key())
+		s.Equal(i > 0, cursor.Prev())
+	}
+	s.False(cursor.Valid())
+
+	// seeking
+	for _, tc := range []struct {
+		key   string
+		seek  string
+		after string
+	}{
+		{"A", "Alice", "Alice"},
+		{"Alice", "Alice", "Felix"},
+		{"Lux", "Marcus", "Marcus"},
+		{"Maya", "Maya", "Mila"},
+		{"Zara", "Zara", ""},
+		{"Zz", "", ""},
+	} {
+		found, err := cursor.Seek(tc.key)
+		s.NoError(err)
+		s.Equal(tc.seek != "", found, tc.key)
+		s.Equal(tc.seek, cursor.Key(), tc.key)
+
+		found, err = cursor.SeekAfter(tc.key)
+		s.NoError(err)
+		s.Equal(tc.after != "", found, tc.key)
+		s.Equal(tc.after, cursor.Key(), tc.key)
+	}
+
+	// paginating
+	pages := make([][]string, 0, 4)
+	var last string
+	for {
+		cursor := tree.Cursor()
+		found, err := cursor.SeekAfter(last)
+		s.NoError(err)
+		page := make([]string, 0, 4)
+		for ; found && len(page) < 4; found = cursor.Next() {
+			page = append(page, cursor.Key())
+		}
+		if len(page) == 0 {
+			break
+		}
+		pages = append(pages, page)
+		last = page[len(page)-1]
+	}
+	s.Equal([][]string{
+		{"Alice", "Felix", "Hugo", "Iris"},
+		{"Kai", "Leo", "Luna", "Marcus"},
+		{"Maya", "Mila", "Nina", "Nora"},
+		{"Oscar", "Theo", "Zara"},
+	}, pages)
+
+	// repositioning after a modification
+	_, err := cursor.Seek("Kai")
+	s.NoError(err)
+	s.NoError(s.Tree.Delete("Leo", nil))
+	_, err = cursor.SeekAfter(cursor.Key())
+	s.NoError(err)
+	s.Equal("Luna", cursor.Key())
+
+	b := s.NewBST(false, &failingComparer{})
+	s.NoError(b.Insert("a", 1))
+	cursor = b.(bst.Navigable[string, int]).Cursor()
+	found, err := cursor.Seek("z")
+	s.ErrorIs(err, errComparison)
+	s.False(found)
+	s.False(cursor.Valid())
+
+	b = s.NewBST(false, comparer.NewComparer[string, int]())
+	cursor = b.(bst.Navigable[string, int]).Cursor()
+	s.False(cursor.First())
+	s.False(cursor.Last())
+	found, err = cursor.Seek("a")
+	s.NoError(err)
+	s.False(found)
+}
+
+func (s *Suite) TestOrderStatistic() {
+	tree, ok := s.Tree.(bst.OrderStatistic[string, int])
+	if !ok {
+		s.T().Skip("tree does not implement bst.OrderStatistic")
+	}
+
+	check := func(keys []string) {
+		for i, key := range keys {
+			rank, err := tree.Rank(key)
+			s.NoError(err)
+			s.Equal(i, rank, key)
+			s.Equal(key, tree.Select(i).Key)
+
+			rank, err = tree.Rank(key + "a")
+			s.NoError(err)
+			s.Equal(i+1, rank, key)
+		}
+		s.Nil(tree.Select(-1))
+		s.Nil(tree.Select(len(keys)))
+
+		for _, query := range s.queries() {
+			count, err := tree.CountRange(query)
+			s.NoError(err)
+			expected := 0
+			for _, k := range keys {
+				if s.matches(k, query) {
+					expected++
+				}
+			}
+			s.Equal(expected, count, "%s", describe(query))
+		}
+	}
+
+	keys := make([]string, 0, len(fixture))
+	for _, entry := range fixture {
+		keys = append(keys, entry.key)
+	}
+	check(keys)
+
+	rank, err := tree.Rank("A")
+	s.NoError(err)
+	s.Equal(0, rank)
+
+	s.NoError(s.Tree.Delete("Alice", nil))
+	s.NoError(s.Tree.Delete("Maya", nil))
+	value := 92
+	s.NoError(s.Tree.Delete("Theo", &value))
+	s.NoError(s.Tree.Insert("Bob", 1))
+	s.NoError(s.Tree.Insert("Nina", 1))
+	check([]string{"Bob", "Felix", "Hugo", "Iris", "Kai", "Leo", "Luna", "Marcus", "Mila", "Nina", "Nora", "Oscar", "Zara"})
+
+	b := s.NewBST(false, &failingComparer{})
+	s.NoError(b.Insert("a", 1))
+	_, err = b.(bst.OrderStatistic[string, int]).Rank("z")
+	s.ErrorIs(err, errComparison)
+	_, err = b.(bst.OrderStatistic[string, int]).CountRange(bst.Query[string]{
+		GreaterThan: &bst.Bound[string]{Value: "0"},
+	})
+	s.ErrorIs(err, errComparison)
+}
+
+// matches reports whether key satisfies query, without using the tree.
+func (s *Suite) matches(key string, query bst.Query[string]) bool {
+	if query.Equal != nil {
+		return key == *query.Equal
+	}
+	if query.GreaterThan == nil && query.LowerThan == nil {
+		return false
+	}
+	if b := query.GreaterThan; b != nil && !(key > b.Value || (key == b.Value && b.IncludeEqual)) {
+		return false
+	}
+	if b := query.LowerThan; b != nil && !(key < b.Value || (key == b.Value && b.IncludeEqual)) {
+		return false
+	}
+	return true
+}
+
+// matchesSet reports whether key belongs to set, without using the tree.
+func (s *Suite) matchesSet(key string, set bst.QuerySet[string]) bool {
+	matchesAny := func(keys []string, ranges []bst.Query[string]) bool {
+		return slices.Contains(keys, key) || slices.ContainsFunc(ranges, func(query bst.Query[string]) bool {
+			return s.matches(key, query)
+		})
+	}
+	return (set.All || matchesAny(set.Keys, set.Ranges)) && !matchesAny(set.ExceptKeys, set.ExceptRanges)
+}
+
+// queries lists every combination of bounds around a few keys of the fixture,
+// some of them present and some of them not.
+func (s *Suite) queries() []bst.Query[string] {
+	keys := []string{"A", "Alice", "Hugo", "Lux", "Maya", "Zara", "Zz"}
+	bounds := make([]*bst.Bound[string], 0, 2*len(keys)+1)
+	bounds = append(bounds, nil)
+	for _, key := range keys {
+		bounds = append(bounds,
+			&bst.Bound[string]{Value: key, IncludeEqual: false},
+			&bst.Bound[string]{Value: key, IncludeEqual: true},
+		)
+	}
+	queries := make([]bst.Query[string], 0, len(bounds)*len(bounds)+len(keys))
+	for _, greaterThan := range bounds {
+		for _, lowerThan := range bounds {
+			queries = append(queries, bst.Query[string]{GreaterThan: greaterThan, LowerThan: lowerThan})
+		}
+	}
+	for _, key := range keys {
+		queries = append(queries, bst.Eq(key))
+	}
+	return queries
+}
+
+func describe(query bst.Query[string]) string {
+	bound := func(b *bst.Bound[string]) string {
+		if b == nil {
+			return "nil"
+		}
+		return fmt.Sprintf("{%s %t}", b.Value, b.IncludeEqual)
+	}
+	if query.Equal != nil {
+		return fmt.Sprintf("Equal: %s", *query.Equal)
+	}
+	return fmt.Sprintf("GreaterThan: %s, LowerThan: %s", bound(query.GreaterThan), bound(query.LowerThan))
+}
+
+// validate checks the structure of tree if it implements bst.Validator.
+func (s *Suite) validate(tree bst.BST[string, int]) {
+	if validator, ok := tree.(bst.Validator); ok {
+		s.NoError(validator.Validate())
+	}
+}
+
+func (s *Suite) assertQuery(expected []int, lowerThan, greaterThan *bst.Bound[string]) {
+	data, err := s.fetch(s.Tree.Query(bst.Query[string]{
+		LowerThan:   lowerThan,
+		GreaterThan: greaterThan,
+	}))
+	s.NoError(err)
+	s.Equal(expected, data)
+}
+
+func (s *Suite) fetch(i iter.Seq2[int, error]) ([]int, error) {
+	res := make([]int, 0, 32)
+	for v, err := range i {
+		if err != nil {
+			return res, err
+		}
+		res = append(res, v)
+	}
+	return res, nil
+}
+
+func (s *Suite) collect(i iter.Seq[int]) []int {
+	res := make([]int, 0, 32)
+	for v := range i {
+		res = append(res, v)
+	}
+	return res
+}
+
+// all returns every value in the fixture, in key order.
+func (s *Suite) all() []int {
+	res := make([]int, 0, 20)
+	for _, entry := range fixture {
+		res = append(res, entry.values...)
+	}
+	return res
+}
+
+func (s *Suite) count(n int) []int {
+	res := make([]int, n)
+	for i := range res {
+		res[i] = i
+	}
+	return res
+}
+
+var errComparison = errors.New("comparison failed")
+
+// failingComparer compares like comparer.Comparer, except that every
+// comparison fails while the corresponding flag is set. Query bounds starting
+// with "0" or "z" fail regardless of the flags.
+type failingComparer struct {
+	failKeys   bool
+	failValues bool
+	// failPair, if set, only fails the comparison of these two keys.
+	failPair *[2]string
+}
+
+func (c *failingComparer) CompareKeys(a string, b string) (int, error) {
+	if c.failPair != nil {
+		if *c.failPair == [2]string{a, b} {
+			return 0, errComparison
+		}
+		return comparer.NewComparer[string, int]().CompareKeys(a, b)
+	}
+	if c.failKeys || b == "0" || b == "z" {
+		return 0, errComparison
+	}
+	return comparer.NewComparer[string, int]().CompareKeys(a, b)
+}
+
+func (c *failingComparer) CompareValues(a int, b int) (bool, error) {
+	if c.failValues {
+		return false, errComparison
+	}
+	return a == b, nil
+}
@@ -0,0 +1,126 @@
+package bst
+
+import "fmt"
+
+// Invariant names a structural property that every tree must hold.
+type Invariant string
+
+// Invariants verified by CheckTree. Adapters may report their own invariants
+// through ErrInvalidTree as well.
+const (
+	InvariantOrder  Invariant = "keys are in order"
+	InvariantParent Invariant = "parent pointers match"
+	InvariantCount  Invariant = "number of keys and values matches"
+	InvariantUnique Invariant = "unique nodes hold one value"
+	InvariantValues Invariant = "nodes hold values"
+)
+
+// Validator is implemented by trees able to check their own structure.
+type Validator interface {
+	Validate() error
+}
+
+// ErrInvalidTree is returned when a tree fails a structural check. Key is the
+// key of the offending node, or nil if the failure is not tied to a node.
+type ErrInvalidTree struct {
+	Key       any
+	Invariant Invariant
+	Detail    string
+}
+
+func (e ErrInvalidTree) Error() string {
+	if e.Key == nil {
+		return fmt.Sprintf("invalid tree: %s: %s", e.Invariant, e.Detail)
+	}
+	return fmt.Sprintf("invalid tree: %s: node %v: %s", e.Invariant, e.Key, e.Detail)
+}
+
+// CheckTree walks every node under root and verifies that keys are in
+// ascending order according to comparer, that every child points back to its
+// parent, that there are exactly numberOfKeys nodes holding numberOfValues
+// values in total, that no node is empty and that, if unique is set, no node
+// holds more than one value. It returns an ErrInvalidTree describing the first
+// broken invariant, or the error returned by comparer. A nil root is an empty
+// tree.
+func CheckTree[K any, V any](root *Node[K, V], comparer Comparer[K, V], unique bool, numberOfKeys int, numberOfValues int) error {
+	return checkTree(checker[K, V]{comparer: comparer, unique: unique}, root, numberOfKeys, numberOfValues)
+}
+
+// CheckShared is CheckTree for trees whose nodes may be shared with other
+// trees, such as persistent ones, and thus cannot point to their parents:
+// instead of matching, parent pointers must all be nil.
+func CheckShared[K any, V any](root *Node[K, V], comparer Comparer[K, V], unique bool, numberOfKeys int, numberOfValues int) error {
+	return checkTree(checker[K, V]{comparer: comparer, unique: unique, shared: true}, root, numberOfKeys, numberOfValues)
+}
+
+func checkTree[K any, V any](c checker[K, V], root *Node[K, V], numberOfKeys int, numberOfValues int) error {
+	if root != nil && root.Parent != nil {
+		return ErrInvalidTree{Key: root.Key, Invariant: InvariantParent, Detail: "root has a parent"}
+	}
+	if err := c.check(root); err != nil {
+		return err
+	}
+	if c.count != numberOfKeys {
+		return ErrInvalidTree{
+			Invariant: InvariantCount,
+			Detail:    fmt.Sprintf("found %d nodes, expected %d", c.count, numberOfKeys),
+		}
+	}
+	if c.values != numberOfValues {
+		return ErrInvalidTree{
+			Invariant: InvariantCount,
+			Detail:    fmt.Sprintf("found %d values, expected %d", c.values, numberOfValues),
+		}
+	}
+	return nil
+}
+
+type checker[K any, V any] struct {
+	comparer Comparer[K, V]
+	unique   bool
+	shared   bool
+	count    int
+	values   int
+	previous *Node[K, V]
+}
+
+// check visits the subtree in order, so that each node only needs to be
+// compared to the one visited right before it.
+func (c *checker[K, V]) check(node *Node[K, V]) error {
+	if node == nil {
+		return nil
+	}
+	for _, child := range [...]*Node[K, V]{node.Lower, node.Greater} {
+		switch {
+		case child == nil:
+		case c.shared && child.Parent != nil:
+			return ErrInvalidTree{Key: child.Key, Invariant: InvariantParent, Detail: "shared node has a parent"}
+		case !c.shared && child.Parent != node:
+			return ErrInvalidTree{Key: child.Key, Invariant: InvariantParent, Detail: fmt.Sprintf("parent is not %v", node.Key)}
+		}
+	}
+	if err := c.check(node.Lower); err != nil {
+		return err
+	}
+
+	c.count++
+	c.values += len(node.Values)
+	switch {
+	case len(node.Values) == 0:
+		return ErrInvalidTree{Key: node.Key, Invariant: InvariantValues, Detail: "no values"}
+	case c.unique && len(node.Values) > 1:
+		return ErrInvalidTree{Key: node.Key, Invariant: InvariantUnique, Detail: fmt.Sprintf("%d values", len(node.Values))}
+	}
+	if c.previous != nil {
+		comparison, err := c.comparer.CompareKeys(c.previous.Key, node.Key)
+		if err != nil {
+			return err
+		}
+		if comparison >= 0 {
+			return ErrInvalidTree{Key: node.Key, Invariant: InvariantOrder, Detail: fmt.Sprintf("not greater than %v", c.previous.Key)}
+		}
+	}
+	c.previous = node
+
+	return c.check(node.Greater)
+}
@@ -0,0 +1,147 @@
+package bst
+
+// Navigable is implemented by trees that can hand out cursors.
+type Navigable[K any, V any] interface {
+	Cursor() *Cursor[K, V]
+}
+
+// Cursor is a position in a tree made of Node values. It moves between
+// neighbouring keys through the Lower, Greater and Parent links, so stepping
+// costs O(1) amortised instead of a new descent from the root.
+//
+// A new cursor is not positioned: call First, Last, Seek or SeekAfter before
+// reading it. Inserting or deleting keys may move or free the node the cursor
+// stands on; after modifying the tree, reposition the cursor with
+// Seek(c.Key()) before stepping again.
+type Cursor[K any, V any] struct {
+	root     func() *Node[K, V]
+	comparer Comparer[K, V]
+	node     *Node[K, V]
+}
+
+// NewCursor creates a cursor over the tree whose current root is returned by
+// root, which may return nil for an empty tree.
+func NewCursor[K any, V any](root func() *Node[K, V], comparer Comparer[K, V]) *Cursor[K, V] {
+	return &Cursor[K, V]{root: root, comparer: comparer}
+}
+
+// Valid reports whether the cursor stands on a node.
+func (c *Cursor[K, V]) Valid() bool {
+	return c.node != nil
+}
+
+// Key returns the key under the cursor, or the zero value if the cursor is
+// not valid.
+func (c *Cursor[K, V]) Key() K {
+	if c.node == nil {
+		return *new(K)
+	}
+	return c.node.Key
+}
+
+// Values returns the values under the cursor, or nil if the cursor is not
+// valid. The slice belongs to the tree and must not be modified.
+func (c *Cursor[K, V]) Values() []V {
+	if c.node == nil {
+		return nil
+	}
+	return c.node.Values
+}
+
+// First moves the cursor to the lowest key and reports whether there is one.
+func (c *Cursor[K, V]) First() bool {
+	c.node = c.root()
+	if c.node != nil {
+		c.node = lowest(c.node)
+	}
+	return c.node != nil
+}
+
+// Last moves the cursor to the greatest key and reports whether there is one.
+func (c *Cursor[K, V]) Last() bool {
+	c.node = c.root()
+	if c.node != nil {
+		c.node = greatest(c.node)
+	}
+	return c.node != nil
+}
+
+// Seek moves the cursor to the lowest key greater than or equal to key and
+// reports whether there is one.
+func (c *Cursor[K, V]) Seek(key K) (bool, error) {
+	return c.seek(key, true)
+}
+
+// SeekAfter moves the cursor to the lowest key strictly greater than key and
+// reports whether there is one. It is meant to resume a scan that stopped at
+// key.
+func (c *Cursor[K, V]) SeekAfter(key K) (bool, error) {
+	return c.seek(key, false)
+}
+
+func (c *Cursor[K, V]) seek(key K, includeEqual bool) (bool, error) {
+	var candidate *Node[K, V]
+	node := c.root()
+	for node != nil {
+		comparison, err := c.comparer.CompareKeys(node.Key, key)
+		if err != nil {
+			c.node = nil
+			return false, err
+		}
+		switch {
+		case comparison > 0 || (comparison == 0 && includeEqual):
+			candidate = node
+			node = node.Lower
+		default:
+			node = node.Greater
+		}
+	}
+	c.node = candidate
+	return c.node != nil, nil
+}
+
+// Next moves the cursor to the following key and reports whether there is
+// one. Moving past the greatest key invalidates the cursor.
+func (c *Cursor[K, V]) Next() bool {
+	switch {
+	case c.node == nil:
+	case c.node.Greater != nil:
+		c.node = lowest(c.node.Greater)
+	default:
+		for c.node.Parent != nil && c.node == c.node.Parent.Greater {
+			c.node = c.node.Parent
+		}
+		c.node = c.node.Parent
+	}
+	return c.node != nil
+}
+
+// Prev moves the cursor to the preceding key and reports whether there is
+// one. Moving past the lowest key invalidates the cursor.
+func (c *Cursor[K, V]) Prev() bool {
+	switch {
+	case c.node == nil:
+	case c.node.Lower != nil:
+		c.node = greatest(c.node.Lower)
+	default:
+		for c.node.Parent != nil && c.node == c.node.Parent.Lower {
+			c.node = c.node.Parent
+		}
+		c.node = c.node.Parent
+	}
+	return c.node != nil
+}
+
+func lowest[K any, V any](node *Node[K, V]) *Node[K, V] {
+	for node.Lower != nil {
+		node = node.Lower
+	}
+	return node
+}
+
+func greatest[K any, V any](node *Node[K, V]) *Node[K, V] {
+	for node.Greater != nil {
+		node = node.Greater
+	}
+	return node
+}
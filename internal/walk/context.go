@@ -0,0 +1,97 @@
+package walk
+
+import (
+	"context"
+	"iter"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// checkInterval is the number of comparisons or nodes visited between two
+// checks of a context.
+const checkInterval = 64
+
+// QueryContext is Query stopping with the error of ctx once ctx is done. It
+// checks ctx before starting and then every checkInterval key comparisons, so
+// that even a scan yielding nothing can be cancelled.
+func QueryContext[K any, V any](ctx context.Context, comparer bst.Comparer[K, V], root *bst.Node[K, V], query bst.Query[K]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(*new(V), err)
+			return
+		}
+		c := &contextComparer[K, V]{Comparer: comparer, ctx: ctx}
+		for value, err := range Query(c, root, query) {
+			if !yield(value, err) {
+				return
+			}
+		}
+	}
+}
+
+// ValuesContext is Values stopping with the error of ctx once ctx is done. It
+// checks ctx before starting and then every checkInterval nodes.
+func ValuesContext[K any, V any](ctx context.Context, root *bst.Node[K, V]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(*new(V), err)
+			return
+		}
+		visited := 0
+		for node := range Nodes(root) {
+			if visited++; visited%checkInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					yield(*new(V), err)
+					return
+				}
+			}
+			for _, v := range node.Values {
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// GroupsContext is Groups stopping with the error of ctx once ctx is done,
+// each key being paired with its values in a bst.Entry. It checks ctx before
+// starting and then every checkInterval nodes.
+func GroupsContext[K any, V any](ctx context.Context, root *bst.Node[K, V]) iter.Seq2[bst.Entry[K, []V], error] {
+	return func(yield func(bst.Entry[K, []V], error) bool) {
+		if err := ctx.Err(); err != nil {
+			yield(bst.Entry[K, []V]{}, err)
+			return
+		}
+		visited := 0
+		for node := range Nodes(root) {
+			if visited++; visited%checkInterval == 0 {
+				if err := ctx.Err(); err != nil {
+					yield(bst.Entry[K, []V]{}, err)
+					return
+				}
+			}
+			if !yield(bst.Entry[K, []V]{Key: node.Key, Value: node.Values}, nil) {
+				return
+			}
+		}
+	}
+}
+
+// contextComparer fails with the error of ctx once it is done, checking it
+// every checkInterval key comparisons.
+type contextComparer[K any, V any] struct {
+	bst.Comparer[K, V]
+	ctx      context.Context
+	compared int
+}
+
+func (c *contextComparer[K, V]) CompareKeys(a, b K) (int, error) {
+	if c.compared%checkInterval == 0 {
+		if err := c.ctx.Err(); err != nil {
+			return 0, err
+		}
+	}
+	c.compared++
+	return c.Comparer.CompareKeys(a, b)
+}
@@ -0,0 +1,102 @@
+package walk
+
+import (
+	"iter"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// ValuesDesc yields every value in the subtree rooted at root in the reverse
+// order of Values: from the greatest key down, and from the last value of each
+// node to the first.
+func ValuesDesc[K any, V any](root *bst.Node[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		if root == nil {
+			return
+		}
+		_ = valuesDesc(root, yield)
+	}
+}
+
+func valuesDesc[K any, V any](node *bst.Node[K, V], yield func(V) bool) bool {
+	if node.Greater != nil && !valuesDesc(node.Greater, yield) {
+		return false
+	}
+	for i := len(node.Values) - 1; i >= 0; i-- {
+		if !yield(node.Values[i]) {
+			return false
+		}
+	}
+	if node.Lower == nil {
+		return true
+	}
+	return valuesDesc(node.Lower, yield)
+}
+
+// QueryDesc yields the same values as Query in reverse order. Only the
+// subtrees that may hold keys within the bounds are visited, so stopping after
+// the first n values costs O(log n + n) on a balanced tree.
+func QueryDesc[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], query bst.Query[K]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		for node, err := range QueryNodesDesc(comparer, root, query) {
+			if err != nil {
+				yield(*new(V), err)
+				return
+			}
+			for i := len(node.Values) - 1; i >= 0; i-- {
+				if !yield(node.Values[i], nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// QueryNodesDesc yields the same nodes as QueryNodes in reverse order.
+func QueryNodesDesc[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], query bst.Query[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return func(yield func(*bst.Node[K, V], error) bool) {
+		if root == nil || (query.GreaterThan == nil && query.LowerThan == nil && query.Equal == nil) {
+			return
+		}
+		q := querier[K, V]{comparer: comparer, yield: yield}
+		if query.Equal != nil {
+			_ = q.queryEqual(root, *query.Equal)
+			return
+		}
+		_ = q.queryDesc(root, query)
+	}
+}
+
+func (q querier[K, V]) queryDesc(node *bst.Node[K, V], query bst.Query[K]) bool {
+	// with no bound on a side, that side is treated as already inside the
+	// range and as having more keys beyond the node.
+	aboveMin, belowMax := true, true
+	greaterSide, lowerSide := true, true
+	if query.LowerThan != nil {
+		comp, err := q.comparer.CompareKeys(node.Key, query.LowerThan.Value)
+		if err != nil {
+			return q.fail(err)
+		}
+		belowMax = comp < 0 || (comp == 0 && query.LowerThan.IncludeEqual)
+		greaterSide = comp < 0
+	}
+	if query.GreaterThan != nil {
+		comp, err := q.comparer.CompareKeys(node.Key, query.GreaterThan.Value)
+		if err != nil {
+			return q.fail(err)
+		}
+		aboveMin = comp > 0 || (comp == 0 && query.GreaterThan.IncludeEqual)
+		lowerSide = comp > 0
+	}
+
+	if greaterSide && node.Greater != nil && !q.queryDesc(node.Greater, query) {
+		return false
+	}
+	if aboveMin && belowMax && !q.yield(node, nil) {
+		return false
+	}
+	if lowerSide && node.Lower != nil {
+		return q.queryDesc(node.Lower, query)
+	}
+	return true
+}
@@ -0,0 +1,17 @@
+package walk
+
+import (
+	"unsafe"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// Outer returns the T in which n is embedded. Balanced adapters keep their
+// bookkeeping, such as heights or colors, in a node type of their own that
+// embeds the bst.Node handed out to callers. Links between nodes are
+// *bst.Node, so each adapter converts them back with Outer, which is only
+// valid if bst.Node is the first field of T and every node of the tree was
+// allocated as a T.
+func Outer[T any, K any, V any](n *bst.Node[K, V]) *T {
+	return (*T)(unsafe.Pointer(n))
+}
@@ -0,0 +1,41 @@
+package walk
+
+import (
+	"iter"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// QueryRange yields, in ascending key order, the values of every node in the
+// subtree rooted at root located inside its range by locate.
+func QueryRange[K any, V any](root *bst.Node[K, V], locate bst.RangeFunc[K]) iter.Seq2[V, error] {
+	return flatten(QueryRangeNodes(root, locate))
+}
+
+// QueryRangeNodes yields, in ascending key order, every node in the subtree
+// rooted at root located inside its range by locate. Nodes before or after
+// the range only lead to their subtree on the side of the range.
+func QueryRangeNodes[K any, V any](root *bst.Node[K, V], locate bst.RangeFunc[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return func(yield func(*bst.Node[K, V], error) bool) {
+		q := querier[K, V]{yield: yield}
+		_ = q.queryRange(root, locate)
+	}
+}
+
+func (q querier[K, V]) queryRange(node *bst.Node[K, V], locate bst.RangeFunc[K]) bool {
+	for node != nil {
+		position, err := locate(node.Key)
+		if err != nil {
+			return q.fail(err)
+		}
+		switch {
+		case position < 0:
+			node = node.Greater
+		case position > 0:
+			node = node.Lower
+		default:
+			return q.queryRange(node.Lower, locate) && q.yield(node, nil) && q.queryRange(node.Greater, locate)
+		}
+	}
+	return true
+}
@@ -0,0 +1,50 @@
+package walk
+
+import "github.com/vinicius-lino-figueiredo/bst"
+
+// Floor returns the node holding the greatest key lower than or equal to key.
+func Floor[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], key K) (*bst.Node[K, V], bool, error) {
+	return nearest(comparer, root, key, false, true)
+}
+
+// Ceiling returns the node holding the lowest key greater than or equal to
+// key.
+func Ceiling[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], key K) (*bst.Node[K, V], bool, error) {
+	return nearest(comparer, root, key, true, true)
+}
+
+// Predecessor returns the node holding the greatest key lower than key.
+func Predecessor[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], key K) (*bst.Node[K, V], bool, error) {
+	return nearest(comparer, root, key, false, false)
+}
+
+// Successor returns the node holding the lowest key greater than key.
+func Successor[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], key K) (*bst.Node[K, V], bool, error) {
+	return nearest(comparer, root, key, true, false)
+}
+
+// nearest descends once from root looking for the node closest to key on the
+// side given by greater, remembering the last node passed on that side of the
+// path.
+func nearest[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], key K, greater, inclusive bool) (*bst.Node[K, V], bool, error) {
+	var closest *bst.Node[K, V]
+	for node := root; node != nil; {
+		comparison, err := comparer.CompareKeys(key, node.Key)
+		if err != nil {
+			return nil, false, err
+		}
+		switch {
+		case comparison == 0 && inclusive:
+			return node, true, nil
+		case greater && comparison < 0:
+			closest, node = node, node.Lower
+		case greater:
+			node = node.Greater
+		case comparison > 0:
+			closest, node = node, node.Greater
+		default:
+			node = node.Lower
+		}
+	}
+	return closest, closest != nil, nil
+}
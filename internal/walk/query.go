@@ -0,0 +1,232 @@
+package walk
+
+import (
+	"iter"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// Query yields, in ascending key order, the values of every node in the
+// subtree rooted at root whose key satisfies query.
+func Query[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], query bst.Query[K]) iter.Seq2[V, error] {
+	return flatten(QueryNodes(comparer, root, query))
+}
+
+// QueryEntries yields the same values as Query, each paired with its key.
+func QueryEntries[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], query bst.Query[K]) iter.Seq2[bst.Entry[K, V], error] {
+	return entries(QueryNodes(comparer, root, query))
+}
+
+// QueryNodes yields, in ascending key order, every node in the subtree rooted
+// at root whose key satisfies query.
+func QueryNodes[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], query bst.Query[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return func(yield func(*bst.Node[K, V], error) bool) {
+		if root == nil {
+			return
+		}
+		q := querier[K, V]{comparer: comparer, yield: yield}
+		switch {
+		case query.Equal != nil:
+			_ = q.queryEqual(root, *query.Equal)
+		case query.GreaterThan != nil:
+			switch query.LowerThan {
+			case nil:
+				_ = q.queryGreater(root, query.GreaterThan)
+			default:
+				_ = q.doubleQuery(root, query)
+			}
+		case query.LowerThan != nil:
+			_ = q.queryLower(root, query.LowerThan)
+		default:
+		}
+	}
+}
+
+type querier[K any, V any] struct {
+	comparer bst.Comparer[K, V]
+	yield    func(*bst.Node[K, V], error) bool
+}
+
+func (q querier[K, V]) fail(err error) bool {
+	q.yield(nil, err)
+	return false
+}
+
+func (q querier[K, V]) queryEqual(root *bst.Node[K, V], key K) bool {
+	node, err := Search(q.comparer, root, key)
+	if err != nil {
+		return q.fail(err)
+	}
+	return node == nil || q.yield(node, nil)
+}
+
+func (q querier[K, V]) doubleQuery(node *bst.Node[K, V], query bst.Query[K]) bool {
+	ltComp, err := q.comparer.CompareKeys(node.Key, query.LowerThan.Value)
+	if err != nil {
+		return q.fail(err)
+	}
+
+	switch {
+	case ltComp > 0:
+		return q.treatAboveMax(node, query)
+	case ltComp < 0:
+		return q.treatBelowMax(node, query)
+	default:
+		return q.treatEqualMax(node, query)
+	}
+}
+
+func (q querier[K, V]) treatAboveMax(node *bst.Node[K, V], query bst.Query[K]) bool {
+	if node.Lower == nil {
+		return true
+	}
+	gtComp, err := q.comparer.CompareKeys(node.Key, query.GreaterThan.Value)
+	if err != nil {
+		return q.fail(err)
+	}
+
+	if gtComp < 0 {
+		return true
+	}
+
+	return q.doubleQuery(node.Lower, query)
+}
+
+func (q querier[K, V]) treatBelowMax(node *bst.Node[K, V], query bst.Query[K]) bool {
+	gtComp, err := q.comparer.CompareKeys(node.Key, query.GreaterThan.Value)
+	if err != nil {
+		return q.fail(err)
+	}
+	switch {
+	case gtComp < 0: // node lower than min
+	case gtComp == 0: // node equal to min
+		if query.GreaterThan.IncludeEqual && !q.yield(node, nil) {
+			return false
+		}
+	default:
+		if node.Lower != nil && !q.queryGreater(node.Lower, query.GreaterThan) {
+			return false
+		}
+		if !q.yield(node, nil) {
+			return false
+		}
+	}
+	if node.Greater != nil {
+		return q.doubleQuery(node.Greater, query)
+	}
+	return true
+}
+
+func (q querier[K, V]) treatEqualMax(node *bst.Node[K, V], query bst.Query[K]) bool {
+	gtComp, err := q.comparer.CompareKeys(node.Key, query.GreaterThan.Value)
+	if err != nil {
+		return q.fail(err)
+	}
+	switch {
+	case gtComp > 0:
+		if node.Lower != nil && !q.queryGreater(node.Lower, query.GreaterThan) {
+			return false
+		}
+		if query.LowerThan.IncludeEqual {
+			return q.yield(node, nil)
+		}
+	case gtComp < 0:
+	default:
+		if query.GreaterThan.IncludeEqual && query.LowerThan.IncludeEqual {
+			return q.yield(node, nil)
+		}
+	}
+	return true
+}
+
+func (q querier[K, V]) queryGreater(node *bst.Node[K, V], bound *bst.Bound[K]) bool {
+	comp, err := q.comparer.CompareKeys(node.Key, bound.Value)
+	if err != nil {
+		return q.fail(err)
+	}
+	switch {
+	case comp > 0:
+		if node.Lower != nil && !q.queryGreater(node.Lower, bound) {
+			return false
+		}
+		if !q.yield(node, nil) {
+			return false
+		}
+	case comp < 0:
+	default:
+		if bound.IncludeEqual && !q.yield(node, nil) {
+			return false
+		}
+	}
+	if node.Greater != nil {
+		return q.queryGreater(node.Greater, bound)
+	}
+	return true
+}
+
+func (q querier[K, V]) queryLower(node *bst.Node[K, V], bound *bst.Bound[K]) bool {
+	comp, err := q.comparer.CompareKeys(node.Key, bound.Value)
+	if err != nil {
+		return q.fail(err)
+	}
+
+	switch {
+	case comp < 0:
+		if node.Lower != nil && !q.queryLower(node.Lower, bound) {
+			return false
+		}
+		if !q.yield(node, nil) {
+			return false
+		}
+		if node.Greater != nil && !q.queryLower(node.Greater, bound) {
+			return false
+		}
+	case comp > 0:
+		if node.Lower != nil {
+			return q.queryLower(node.Lower, bound)
+		}
+	default:
+		if node.Lower != nil && !q.queryLower(node.Lower, bound) {
+			return false
+		}
+		if bound.IncludeEqual && !q.yield(node, nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// flatten turns a sequence of nodes into the sequence of their values.
+func flatten[K any, V any](nodes iter.Seq2[*bst.Node[K, V], error]) iter.Seq2[V, error] {
+	return func(yield func(V, error) bool) {
+		for node, err := range nodes {
+			if err != nil {
+				yield(*new(V), err)
+				return
+			}
+			for _, v := range node.Values {
+				if !yield(v, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// entries turns a sequence of nodes into the sequence of their values paired
+// with their keys.
+func entries[K any, V any](nodes iter.Seq2[*bst.Node[K, V], error]) iter.Seq2[bst.Entry[K, V], error] {
+	return func(yield func(bst.Entry[K, V], error) bool) {
+		for node, err := range nodes {
+			if err != nil {
+				yield(bst.Entry[K, V]{}, err)
+				return
+			}
+			for _, v := range node.Values {
+				if !yield(bst.Entry[K, V]{Key: node.Key, Value: v}, nil) {
+					return
+				}
+			}
+		}
+	}
+}
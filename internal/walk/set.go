@@ -0,0 +1,343 @@
+package walk
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// interval holds the keys between lower and upper, each of them being nil if
+// the interval is unbounded on that side.
+type interval[K any] struct {
+	lower, upper *bst.Bound[K]
+}
+
+// QueryIn yields, in ascending key order, the values of every node in the
+// subtree rooted at root whose key belongs to set.
+func QueryIn[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], set bst.QuerySet[K]) iter.Seq2[V, error] {
+	return flatten(QueryInNodes(comparer, root, set))
+}
+
+// QueryInNodes yields, in ascending key order, every node in the subtree
+// rooted at root whose key belongs to set. The set is first turned into
+// sorted disjoint intervals; every node then hands each of its subtrees the
+// intervals reaching into it, so that subtrees no interval reaches are never
+// visited.
+func QueryInNodes[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], set bst.QuerySet[K]) iter.Seq2[*bst.Node[K, V], error] {
+	return func(yield func(*bst.Node[K, V], error) bool) {
+		if root == nil {
+			return
+		}
+		q := querier[K, V]{comparer: comparer, yield: yield}
+		intervals, err := q.normalize(set)
+		if err != nil {
+			q.fail(err)
+			return
+		}
+		_ = q.queryIn(root, intervals)
+	}
+}
+
+func (q querier[K, V]) queryIn(node *bst.Node[K, V], intervals []interval[K]) bool {
+	if node == nil || len(intervals) == 0 {
+		return true
+	}
+	// intervals reaching below the key of node come first, those reaching
+	// above it last; only the ones around the boundary may hold the key.
+	below, err := q.partition(intervals, func(i interval[K]) (bool, error) {
+		reaches, err := q.reachesBelow(i, node.Key)
+		return !reaches, err
+	})
+	if err != nil {
+		return q.fail(err)
+	}
+	above, err := q.partition(intervals, func(i interval[K]) (bool, error) {
+		return q.reachesAbove(i, node.Key)
+	})
+	if err != nil {
+		return q.fail(err)
+	}
+
+	if !q.queryIn(node.Lower, intervals[:below]) {
+		return false
+	}
+	for _, i := range intervals[max(below-1, 0):min(below+1, len(intervals))] {
+		contains, err := q.contains(i, node.Key)
+		if err != nil {
+			return q.fail(err)
+		}
+		if contains {
+			if !q.yield(node, nil) {
+				return false
+			}
+			break
+		}
+	}
+	return q.queryIn(node.Greater, intervals[above:])
+}
+
+// normalize turns set into sorted, disjoint and non-empty intervals.
+func (q querier[K, V]) normalize(set bst.QuerySet[K]) ([]interval[K], error) {
+	included := []interval[K]{{}}
+	if !set.All {
+		var err error
+		if included, err = q.merge(set.Keys, set.Ranges); err != nil {
+			return nil, err
+		}
+	}
+	if len(set.ExceptKeys) == 0 && len(set.ExceptRanges) == 0 {
+		return included, nil
+	}
+	excluded, err := q.merge(set.ExceptKeys, set.ExceptRanges)
+	if err != nil {
+		return nil, err
+	}
+	return q.subtract(included, excluded)
+}
+
+// merge turns keys and ranges into sorted, disjoint and non-empty intervals.
+func (q querier[K, V]) merge(keys []K, ranges []bst.Query[K]) ([]interval[K], error) {
+	intervals := make([]interval[K], 0, len(keys)+len(ranges))
+	for _, key := range keys {
+		bound := &bst.Bound[K]{Value: key, IncludeEqual: true}
+		intervals = append(intervals, interval[K]{lower: bound, upper: bound})
+	}
+	for _, r := range ranges {
+		if r.Equal != nil {
+			bound := &bst.Bound[K]{Value: *r.Equal, IncludeEqual: true}
+			intervals = append(intervals, interval[K]{lower: bound, upper: bound})
+		} else if r.GreaterThan != nil || r.LowerThan != nil {
+			intervals = append(intervals, interval[K]{lower: r.GreaterThan, upper: r.LowerThan})
+		}
+	}
+
+	var err error
+	slices.SortFunc(intervals, func(a, b interval[K]) int {
+		comparison, compErr := q.compareLower(a.lower, b.lower)
+		if compErr != nil && err == nil {
+			err = compErr
+		}
+		return comparison
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	merged := intervals[:0]
+	for _, i := range intervals {
+		empty, err := q.empty(i)
+		if err != nil {
+			return nil, err
+		}
+		if empty {
+			continue
+		}
+		if len(merged) > 0 {
+			last := &merged[len(merged)-1]
+			overlaps, err := q.overlaps(*last, i)
+			if err != nil {
+				return nil, err
+			}
+			if overlaps {
+				comparison, err := q.compareUpper(last.upper, i.upper)
+				if err != nil {
+					return nil, err
+				}
+				if comparison < 0 {
+					last.upper = i.upper
+				}
+				continue
+			}
+		}
+		merged = append(merged, i)
+	}
+	return merged, nil
+}
+
+// subtract removes the keys of excluded from included, both of them being
+// sorted and disjoint intervals, and so is the result.
+func (q querier[K, V]) subtract(included, excluded []interval[K]) ([]interval[K], error) {
+	result := make([]interval[K], 0, len(included)+len(excluded))
+	next := 0
+	for _, current := range included {
+		remaining := true
+		for ; next < len(excluded); next++ {
+			e := excluded[next]
+			before, err := q.before(e, current)
+			if err != nil {
+				return nil, err
+			}
+			if before {
+				continue
+			}
+			if before, err = q.before(current, e); err != nil {
+				return nil, err
+			}
+			if before {
+				break
+			}
+
+			// e overlaps current: keep what comes before it, and go on with
+			// what comes after it, if anything.
+			if e.lower != nil {
+				kept := interval[K]{lower: current.lower, upper: &bst.Bound[K]{Value: e.lower.Value, IncludeEqual: !e.lower.IncludeEqual}}
+				empty, err := q.empty(kept)
+				if err != nil {
+					return nil, err
+				}
+				if !empty {
+					result = append(result, kept)
+				}
+			}
+			if e.upper == nil {
+				remaining = false
+				break
+			}
+			current.lower = &bst.Bound[K]{Value: e.upper.Value, IncludeEqual: !e.upper.IncludeEqual}
+			empty, err := q.empty(current)
+			if err != nil {
+				return nil, err
+			}
+			if empty {
+				remaining = false
+				break
+			}
+		}
+		if remaining {
+			result = append(result, current)
+		}
+	}
+	return result, nil
+}
+
+// before reports whether every key of a is lower than every key of b.
+func (q querier[K, V]) before(a, b interval[K]) (bool, error) {
+	if a.upper == nil || b.lower == nil {
+		return false, nil
+	}
+	comparison, err := q.comparer.CompareKeys(a.upper.Value, b.lower.Value)
+	if err != nil {
+		return false, err
+	}
+	return comparison < 0 || (comparison == 0 && !(a.upper.IncludeEqual && b.lower.IncludeEqual)), nil
+}
+
+// compareLower orders lower bounds by the first key they admit.
+func (q querier[K, V]) compareLower(a, b *bst.Bound[K]) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return -1, nil
+	case b == nil:
+		return 1, nil
+	}
+	comparison, err := q.comparer.CompareKeys(a.Value, b.Value)
+	if err != nil || comparison != 0 || a.IncludeEqual == b.IncludeEqual {
+		return comparison, err
+	}
+	if a.IncludeEqual {
+		return -1, nil
+	}
+	return 1, nil
+}
+
+// compareUpper orders upper bounds by the last key they admit.
+func (q querier[K, V]) compareUpper(a, b *bst.Bound[K]) (int, error) {
+	switch {
+	case a == nil && b == nil:
+		return 0, nil
+	case a == nil:
+		return 1, nil
+	case b == nil:
+		return -1, nil
+	}
+	comparison, err := q.comparer.CompareKeys(a.Value, b.Value)
+	if err != nil || comparison != 0 || a.IncludeEqual == b.IncludeEqual {
+		return comparison, err
+	}
+	if a.IncludeEqual {
+		return 1, nil
+	}
+	return -1, nil
+}
+
+// empty reports whether no key fits in i.
+func (q querier[K, V]) empty(i interval[K]) (bool, error) {
+	if i.lower == nil || i.upper == nil {
+		return false, nil
+	}
+	comparison, err := q.comparer.CompareKeys(i.lower.Value, i.upper.Value)
+	if err != nil {
+		return false, err
+	}
+	return comparison > 0 || (comparison == 0 && !(i.lower.IncludeEqual && i.upper.IncludeEqual)), nil
+}
+
+// overlaps reports whether next, which does not start before last, starts
+// before last ends or right where it ends, so that both can be merged.
+func (q querier[K, V]) overlaps(last, next interval[K]) (bool, error) {
+	if last.upper == nil || next.lower == nil {
+		return true, nil
+	}
+	comparison, err := q.comparer.CompareKeys(next.lower.Value, last.upper.Value)
+	if err != nil {
+		return false, err
+	}
+	return comparison < 0 || (comparison == 0 && (next.lower.IncludeEqual || last.upper.IncludeEqual)), nil
+}
+
+// reachesBelow reports whether i holds keys lower than key.
+func (q querier[K, V]) reachesBelow(i interval[K], key K) (bool, error) {
+	if i.lower == nil {
+		return true, nil
+	}
+	comparison, err := q.comparer.CompareKeys(i.lower.Value, key)
+	return comparison < 0, err
+}
+
+// reachesAbove reports whether i holds keys greater than key.
+func (q querier[K, V]) reachesAbove(i interval[K], key K) (bool, error) {
+	if i.upper == nil {
+		return true, nil
+	}
+	comparison, err := q.comparer.CompareKeys(i.upper.Value, key)
+	return comparison > 0, err
+}
+
+// contains reports whether key fits in i.
+func (q querier[K, V]) contains(i interval[K], key K) (bool, error) {
+	if i.lower != nil {
+		comparison, err := q.comparer.CompareKeys(key, i.lower.Value)
+		if err != nil || comparison < 0 || (comparison == 0 && !i.lower.IncludeEqual) {
+			return false, err
+		}
+	}
+	if i.upper != nil {
+		comparison, err := q.comparer.CompareKeys(key, i.upper.Value)
+		if err != nil || comparison > 0 || (comparison == 0 && !i.upper.IncludeEqual) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// partition returns the index of the first of intervals satisfying pred,
+// which must hold for every interval after it as well.
+func (q querier[K, V]) partition(intervals []interval[K], pred func(interval[K]) (bool, error)) (int, error) {
+	low, high := 0, len(intervals)
+	for low < high {
+		mid := int(uint(low+high) >> 1)
+		ok, err := pred(intervals[mid])
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
+	return low, nil
+}
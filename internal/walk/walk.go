@@ -0,0 +1,182 @@
+// Package walk implements the read-side traversals shared by the adapters
+// that lay their trees out as bst.Node values linked through Lower, Greater
+// and Parent.
+package walk
+
+import (
+	"iter"
+	"slices"
+
+	"github.com/vinicius-lino-figueiredo/bst"
+)
+
+// Search descends from root looking for the node holding key. It returns nil
+// when root is nil or the key is absent.
+func Search[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], key K) (*bst.Node[K, V], error) {
+	node := root
+	for node != nil {
+		comparison, err := comparer.CompareKeys(key, node.Key)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case comparison > 0:
+			node = node.Greater
+		case comparison < 0:
+			node = node.Lower
+		default:
+			return node, nil
+		}
+	}
+	return nil, nil
+}
+
+// Min returns the node with the lowest key in the subtree rooted at node.
+func Min[K any, V any](node *bst.Node[K, V]) *bst.Node[K, V] {
+	for node.Lower != nil {
+		node = node.Lower
+	}
+	return node
+}
+
+// Max returns the node with the greatest key in the subtree rooted at node.
+func Max[K any, V any](node *bst.Node[K, V]) *bst.Node[K, V] {
+	for node.Greater != nil {
+		node = node.Greater
+	}
+	return node
+}
+
+// Height returns the number of nodes on the longest path from root down to a
+// leaf, visiting every node of the subtree.
+func Height[K any, V any](root *bst.Node[K, V]) int {
+	if root == nil {
+		return 0
+	}
+	return 1 + max(Height(root.Lower), Height(root.Greater))
+}
+
+// Values yields every value in the subtree rooted at root, in ascending key
+// order.
+func Values[K any, V any](root *bst.Node[K, V]) iter.Seq[V] {
+	return func(yield func(V) bool) {
+		for node := range Nodes(root) {
+			for _, value := range node.Values {
+				if !yield(value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Entries yields every value in the subtree rooted at root along with its
+// key, in ascending key order.
+func Entries[K any, V any](root *bst.Node[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for node := range Nodes(root) {
+			for _, value := range node.Values {
+				if !yield(node.Key, value) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Groups yields every key in the subtree rooted at root along with all of
+// its values, in ascending key order.
+func Groups[K any, V any](root *bst.Node[K, V]) iter.Seq2[K, []V] {
+	return func(yield func(K, []V) bool) {
+		for node := range Nodes(root) {
+			if !yield(node.Key, node.Values) {
+				return
+			}
+		}
+	}
+}
+
+// Nodes yields every node in the subtree rooted at root, in ascending key
+// order.
+func Nodes[K any, V any](root *bst.Node[K, V]) iter.Seq[*bst.Node[K, V]] {
+	return func(yield func(*bst.Node[K, V]) bool) {
+		if root == nil {
+			return
+		}
+		_ = nodes(root, yield)
+	}
+}
+
+func nodes[K any, V any](node *bst.Node[K, V], yield func(*bst.Node[K, V]) bool) bool {
+	if node.Lower != nil && !nodes(node.Lower, yield) {
+		return false
+	}
+	if !yield(node) {
+		return false
+	}
+	if node.Greater == nil {
+		return true
+	}
+	return nodes(node.Greater, yield)
+}
+
+// ReplaceValue replaces the first value of node that the comparer considers
+// equal to old with nw, reporting whether there was one.
+func ReplaceValue[K any, V any](comparer bst.Comparer[K, V], node *bst.Node[K, V], old V, nw V) (bool, error) {
+	for n, value := range node.Values {
+		equals, err := comparer.CompareValues(value, old)
+		if err != nil {
+			return false, err
+		}
+		if equals {
+			node.Values[n] = nw
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// DeleteValue removes the first value of node that the comparer considers
+// equal to value.
+func DeleteValue[K any, V any](comparer bst.Comparer[K, V], node *bst.Node[K, V], value *V) error {
+	for n, v := range node.Values {
+		found, err := comparer.CompareValues(*value, v)
+		if err != nil {
+			return err
+		}
+		if found {
+			node.Values = slices.Delete(node.Values, n, n+1)
+			return nil
+		}
+	}
+	return nil
+}
+
+// Remove prepares the deletion of key from the tree rooted at root, or of the
+// first value equal to value only if value is not nil. It returns how many
+// values were removed and, if the key must go, the node to unlink: the node
+// holding key or, if that one has two children, its in-order successor, whose
+// key and values were first moved into it. That node has at most one child.
+func Remove[K any, V any](comparer bst.Comparer[K, V], root *bst.Node[K, V], key K, value *V) (*bst.Node[K, V], int, error) {
+	node, err := Search(comparer, root, key)
+	if err != nil || node == nil {
+		return nil, 0, err
+	}
+	removed := 0
+	if value != nil {
+		n := len(node.Values)
+		err = DeleteValue(comparer, node, value)
+		removed = n - len(node.Values)
+		if err != nil || len(node.Values) > 0 {
+			return nil, removed, err
+		}
+	}
+	removed += len(node.Values)
+
+	if node.Lower != nil && node.Greater != nil {
+		successor := Min(node.Greater)
+		node.Key, node.Values = successor.Key, successor.Values
+		node = successor
+	}
+	return node, removed, nil
+}